@@ -4,19 +4,38 @@ import "time"
 
 // Alerts represents a collection of alerts
 type Alerts struct {
-	Alerts []Alert `json:"alerts"`
+	Alerts []Alert `json:"alerts" schema:"required"`
 }
 
-// Alert represents a single alert with all its properties
+// Alert represents a single alert with all its properties. Fields with a
+// schema tag are read from input files and make up the JSON Schema.
 type Alert struct {
-	ID          string    `json:"id"`
-	Timestamp   time.Time `json:"timestamp"`
-	Service     string    `json:"service"`
-	Component   string    `json:"component"`
-	Severity    string    `json:"severity"`
-	Metric      string    `json:"metric"`
-	Value       float64   `json:"value"`
-	Threshold   float64   `json:"threshold"`
-	Description string    `json:"description"`
+	ID          string            `json:"id" schema:"required"`
+	Timestamp   time.Time         `json:"timestamp" schema:"required"`
+	Service     string            `json:"service" schema:"required"`
+	Component   string            `json:"component" schema:"optional"`
+	Severity    string            `json:"severity" schema:"required"`
+	Metric      string            `json:"metric" schema:"required"`
+	Value       float64           `json:"value" schema:"required"`
+	Threshold   float64           `json:"threshold" schema:"optional"`
+	Description string            `json:"description" schema:"optional"`
+	Labels      map[string]string `json:"labels,omitempty" schema:"optional"`      // Identifying keys, e.g. region or cluster
+	Annotations map[string]string `json:"annotations,omitempty" schema:"optional"` // Descriptive keys, e.g. runbook_url
 	Priority float64 // Calculated field, not read from incoming JSON
+
+	Maintenance       string  `json:"maintenance,omitempty"`   // Down-weighting maintenance window, if any
+	Team              string  `json:"routed_team,omitempty"`   // Receiver from the routing tree, if routed
+	OnCall            string  `json:"on_call,omitempty"`       // Who was on call for Team at Timestamp
+	Flapping          bool    `json:"flapping,omitempty"`      // Set by flapping detection
+	FlapCount         int     `json:"flap_count,omitempty"`    // Firings collapsed into this alert
+	Storm             bool    `json:"storm,omitempty"`         // Part of an alert storm for its service
+	AnomalyScore      float64 `json:"anomaly_score,omitempty"` // How unusual Value is for this identity's history
+	maintenanceFactor float64
+	severityScore     float64 // From the severity ladder the alert was loaded with
+}
+
+// Identity identifies the thing an alert is about, independent of the
+// individual firing: the same service, component and metric
+func (alert Alert) Identity() string {
+	return alert.Service + "/" + alert.Component + "/" + alert.Metric
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// anomalyMethods are the supported ways of scoring a value against its history
+var anomalyMethods = []string{"zscore", "mad", "ewma"}
+
+const (
+	minHistorySamples = 3    // Identities with fewer past values are not scored
+	maxAnomalyScore   = 10.0 // Cap so a single outlier can't swamp the other priority terms
+	ewmaAlpha         = 0.3  // Weight of the newest value in the EWMA baseline
+)
+
+// historyPoint is one past value of an identity
+type historyPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// AnomalyHistory holds the past values of every identity, oldest first
+type AnomalyHistory map[string][]historyPoint
+
+func loadAnomalyHistory(filenames []string, options LoadOptions) (AnomalyHistory, error) {
+	// Parse the files concurrently, then add them in the order given
+	loaded, errs := make([]*Alerts, len(filenames)), make([]error, len(filenames))
+	parallelFor(len(filenames), 1, func(start, end int) {
+		for i := start; i < end; i++ {
+			loaded[i], errs[i] = loadAlertsFromFile(filenames[i], options)
+		}
+	})
+
+	history := make(AnomalyHistory)
+	for i, alerts := range loaded {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("error loading history: %v", errs[i])
+		}
+		history.Add(*alerts)
+	}
+	return history, nil
+}
+
+// Add records the values of the given alerts
+func (history AnomalyHistory) Add(alerts Alerts) {
+	for _, alert := range alerts.Alerts {
+		identity := alert.Identity()
+		history[identity] = append(history[identity], historyPoint{Timestamp: alert.Timestamp, Value: alert.Value})
+	}
+	for _, points := range history {
+		sort.SliceStable(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+	}
+}
+
+// valuesBefore returns the values recorded for an identity strictly before t,
+// so an alert that is also part of the history is never scored against itself
+func (history AnomalyHistory) valuesBefore(identity string, t time.Time) []float64 {
+	var values []float64
+	for _, point := range history[identity] {
+		if !point.Timestamp.Before(t) {
+			break
+		}
+		values = append(values, point.Value)
+	}
+	return values
+}
+
+// ScoreAnomalies sets the anomaly score of every alert: how far its value is
+// from what is usual for the same service, component and metric. Identities
+// that are always noisy have a wide spread and score low.
+func (alerts *Alerts) ScoreAnomalies(history AnomalyHistory, method string) {
+	for i := range alerts.Alerts {
+		alert := &alerts.Alerts[i]
+		alert.AnomalyScore = anomalyScore(method, history.valuesBefore(alert.Identity(), alert.Timestamp), alert.Value)
+	}
+}
+
+// anomalyScore scores a value against past values with the given method
+func anomalyScore(method string, past []float64, value float64) float64 {
+	if len(past) < minHistorySamples {
+		return 0
+	}
+
+	var center, spread float64
+	switch method {
+	case "mad":
+		center = median(past)
+		deviations := make([]float64, len(past))
+		for i, v := range past {
+			deviations[i] = math.Abs(v - center)
+		}
+		// Scale the MAD so it estimates the standard deviation of normal data
+		spread = 1.4826 * median(deviations)
+	case "ewma":
+		center, spread = ewma(past)
+	default:
+		center, spread = meanStdDev(past)
+	}
+
+	deviation := math.Abs(value - center)
+	if deviation == 0 {
+		return 0
+	}
+	if spread == 0 {
+		return maxAnomalyScore // Any change from a constant history is unusual
+	}
+	score := math.Min(deviation/spread, maxAnomalyScore)
+	return math.Round(score*100) / 100
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// ewma returns an exponentially weighted moving average of the values and
+// the matching exponentially weighted standard deviation, so recent values
+// define the baseline
+func ewma(values []float64) (float64, float64) {
+	mean, variance := values[0], 0.0
+	for _, v := range values[1:] {
+		diff := v - mean
+		increment := ewmaAlpha * diff
+		mean += increment
+		variance = (1 - ewmaAlpha) * (variance + diff*increment)
+	}
+	return mean, math.Sqrt(variance)
+}
+
+func showAnomalyReport(w io.Writer, alerts Alerts, history AnomalyHistory, method string) {
+	var samples, unusual int
+	for _, points := range history {
+		samples += len(points)
+	}
+	for _, alert := range alerts.Alerts {
+		if alert.AnomalyScore >= 3 {
+			unusual++
+		}
+	}
+	fmt.Fprintf(w, "📐 Anomaly scoring (%s): %d past values for %d identities, %d unusual alerts\n",
+		method, samples, len(history), unusual)
+}
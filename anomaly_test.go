@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnomalyScore(t *testing.T) {
+	steady := []float64{50, 52, 48, 50, 51, 49}
+
+	tests := []struct {
+		method   string
+		past     []float64
+		value    float64
+		expected func(score float64) bool
+	}{
+		{"zscore", steady, 50, func(score float64) bool { return score == 0 }},
+		{"zscore", steady, 53, func(score float64) bool { return score > 2 && score < 3 }},
+		{"zscore", steady, 90, func(score float64) bool { return score == maxAnomalyScore }},
+		// A single past outlier inflates the standard deviation but not the MAD
+		{"zscore", []float64{50, 51, 49, 50, 500}, 60, func(score float64) bool { return score < 1 }},
+		{"mad", []float64{50, 51, 49, 50, 500}, 60, func(score float64) bool { return score > 5 }},
+		// A rising trend: the EWMA baseline follows it, the mean lags behind
+		{"zscore", []float64{10, 20, 30, 40, 50}, 60, func(score float64) bool { return score > 2 }},
+		{"ewma", []float64{10, 20, 30, 40, 50}, 60, func(score float64) bool { return score < 2 }},
+		{"zscore", []float64{50, 50, 50}, 51, func(score float64) bool { return score == maxAnomalyScore }},
+		{"zscore", []float64{50, 50}, 90, func(score float64) bool { return score == 0 }}, // Too little history
+	}
+
+	for _, test := range tests {
+		score := anomalyScore(test.method, test.past, test.value)
+		if !test.expected(score) {
+			t.Errorf("anomalyScore(%s, %v, %.0f) = %.2f, unexpected", test.method, test.past, test.value, score)
+		}
+	}
+}
+
+func TestScoreAnomalies(t *testing.T) {
+	base := mustParseTime(t, "2024-04-28T10:00:00Z")
+	history := make(AnomalyHistory)
+	var past Alerts
+	for hour := 0; hour < 6; hour++ {
+		at := base.Add(time.Duration(hour) * time.Hour)
+		// The cache is always noisy, the database is always calm
+		past.Alerts = append(past.Alerts,
+			Alert{Service: "web", Component: "cache", Metric: "latency", Value: float64(100 + 80*(hour%2)), Timestamp: at},
+			Alert{Service: "db", Component: "primary", Metric: "latency", Value: float64(100 + hour%2), Timestamp: at},
+		)
+	}
+	history.Add(past)
+
+	now := base.Add(12 * time.Hour)
+	alerts := Alerts{Alerts: []Alert{
+		{ID: "ALT-1", Service: "web", Component: "cache", Metric: "latency", Severity: "warning", Value: 190, Threshold: 150, Timestamp: now},
+		{ID: "ALT-2", Service: "db", Component: "primary", Metric: "latency", Severity: "warning", Value: 190, Threshold: 150, Timestamp: now},
+	}}
+
+	alerts.ScoreAnomalies(history, "zscore")
+	if alerts.Alerts[0].AnomalyScore >= alerts.Alerts[1].AnomalyScore {
+		t.Errorf("Expected the calm database to score higher than the noisy cache, got %.2f and %.2f",
+			alerts.Alerts[1].AnomalyScore, alerts.Alerts[0].AnomalyScore)
+	}
+	// Only values from before an alert count
+	if values := history.valuesBefore("db/primary/latency", base.Add(time.Hour)); len(values) != 1 {
+		t.Errorf("Expected 1 earlier value, got %v", values)
+	}
+
+	alerts.CalculateAllWeightedPriorities(DefaultPriorityWeights())
+	if alerts.Alerts[1].Priority <= alerts.Alerts[0].Priority {
+		t.Errorf("Expected the unusual database alert to rank higher, got %.2f and %.2f",
+			alerts.Alerts[1].Priority, alerts.Alerts[0].Priority)
+	}
+
+	weights := DefaultPriorityWeights()
+	weights.Anomaly = 0
+	alerts.CalculateAllWeightedPriorities(weights)
+	if alerts.Alerts[1].Priority != alerts.Alerts[0].Priority {
+		t.Errorf("Expected equal priorities without the anomaly term, got %.2f and %.2f",
+			alerts.Alerts[1].Priority, alerts.Alerts[0].Priority)
+	}
+}
+
+func TestParseFlags_WithAnomaly(t *testing.T) {
+	resetFlags()
+
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--history", testFile + "," + testFile, "--anomaly=mad", "--anomaly-weight=2.5"}
+
+	config, err := parseFlags()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if config.Anomaly != "mad" || config.weights.Anomaly != 2.5 || config.weights.Severity != 1.0 {
+		t.Errorf("Unexpected anomaly config: %s, %+v", config.Anomaly, config.weights)
+	}
+
+	resetFlags()
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--anomaly=iforest"}
+	_, err = parseFlags()
+	if err == nil || !strings.Contains(err.Error(), "invalid anomaly method") {
+		t.Errorf("Expected error for invalid anomaly method, got: %v", err)
+	}
+
+	resetFlags()
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--history", "missing.json"}
+	if _, err := parseFlags(); err == nil {
+		t.Error("Expected error for missing history file, got nil")
+	}
+}
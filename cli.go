@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"strings"
+	"time"
 )
 
 const (
@@ -13,6 +16,29 @@ const (
 	AppName = "enc-alertbuddy"
 )
 
+// subcommands maps subcommand names to their entry points. Anything else
+// on the command line is handled by the flag-based interface, whose flags
+// still work as aliases: plain -i is top, -a is list, --groupby is group
+// and --trends is stats.
+var subcommands = map[string]func(args []string) error{
+	"top":      runTopCommand,
+	"list":     runListCommand,
+	"group":    runGroupCommand,
+	"stats":    runStatsCommand,
+	"diff":     runDiffCommand,
+	"serve":    runServeCommand,
+	"validate": runValidateCommand,
+	"schema":   runSchemaCommand,
+	"config":   runConfigCommand,
+	"route":    runRouteCommand,
+	"notify":   runNotifyCommand,
+	"digest":   runDigestCommand,
+	"export":   runExportCommand,
+	"generate": runGenerateCommand,
+
+	"simulate-escalation": runSimulateEscalationCommand,
+}
+
 type Config struct {
 	InputFile   string
 	GroupBy     string
@@ -20,18 +46,174 @@ type Config struct {
 	ShowVersion bool
 	ShowHelp    bool
 	ShowAll     bool
+	
+	MaintenanceFile    string
+	maintenanceWindows []MaintenanceWindow // Loaded from MaintenanceFile
+	RoutesFile         string
+	routes             *Route // Loaded from RoutesFile
+	OnCallFile         string
+	schedules          map[string]*OnCallSchedule // Loaded from OnCallFile
+	Detect             bool
+	Detected           string
+	detectors          DetectorConfig
+	notifier           NotifierConfig
+	mail               MailConfig
+	HistoryFiles       string
+	history            AnomalyHistory // Loaded from HistoryFiles
+	Anomaly            string
+	weights            PriorityWeights
+	Trends             string
+	TrendsWindow       time.Duration
+	RankTop            int
+	RankBy             string
+	rankMetrics        []string // Parsed from RankBy
+	Top                int
+	Sort               string
+	sortKeys           []SortKey // Parsed from Sort
+	BlastRadius        string
+	OutputFile         string
+	Format             string
+	MappingFile        string
+	mapping            *FieldMapping
+	SeveritiesFile     string
+	Recursive          bool
+	Include            string
+	Exclude            string
+	severities         *SeverityModel
+	MinSeverity        string
+	Filter             string
+	filters            map[string]string // Parsed from Filter
+	Strict             bool
+	Schema             bool
+	Rules              string
+	ruleLevels         map[string]string // Parsed from Rules
+	TZ                 string
+	Relative           bool
+	display            TimeDisplay // Built from TZ and Relative
+	reports            io.Writer // Where progress and reports go; see reportWriter
+}
+
+// newConfig returns a configuration with every default set
+func newConfig() *Config {
+	return &Config{
+		Anomaly:      "zscore",
+		TrendsWindow: 24 * time.Hour,
+		RankTop:      5,
+		RankBy:       "count",
+		Top:          10,
+		Sort:         defaultSortSpec,
+		BlastRadius:  BlastRadiusFiltered,
+		Format:       "text",
+		detectors:    DefaultDetectorConfig(),
+		notifier:     DefaultNotifierConfig(),
+		mail:         DefaultMailConfig(),
+		weights:      DefaultPriorityWeights(),
+		severities:   defaultSeverities,
+	}
+}
+
+// registerInputFlags defines the input flags shared by all commands
+func registerInputFlags(fs *flag.FlagSet, config *Config) {
+	fs.StringVar(&config.InputFile, "i", config.InputFile, "Input JSON file containing alerts")
+	fs.StringVar(&config.InputFile, "input", config.InputFile, "Input JSON file containing alerts")
+	fs.StringVar(&config.MappingFile, "mapping", config.MappingFile, "JSON file mapping a foreign alert format to alert fields")
+	fs.StringVar(&config.SeveritiesFile, "severities", config.SeveritiesFile, "JSON file with a custom severity ladder, aliases and scores")
+	fs.BoolVar(&config.Recursive, "recursive", config.Recursive, "Read the subdirectories of an input directory too")
+	fs.StringVar(&config.Include, "include", config.Include, "Comma-separated glob patterns of the files read from an input directory")
+	fs.StringVar(&config.Exclude, "exclude", config.Exclude, "Comma-separated glob patterns of files to skip in an input directory")
+}
+
+// registerOutputFlags defines the output flags shared by the subcommands
+func registerOutputFlags(fs *flag.FlagSet, config *Config) {
+	fs.StringVar(&config.OutputFile, "o", config.OutputFile, "Write output to a file instead of stdout")
+	fs.StringVar(&config.OutputFile, "output", config.OutputFile, "Write output to a file instead of stdout")
+	fs.StringVar(&config.Format, "format", config.Format, "Output format (text, json)")
+}
+
+// registerDisplayFlags defines the flags that pick what the flag-based
+// interface shows
+func registerDisplayFlags(fs *flag.FlagSet, config *Config) {
+	fs.StringVar(&config.GroupBy, "groupby", config.GroupBy, "Group alerts by field (severity, service, component, metric, labels.<name>, etc.)")
+	fs.BoolVar(&config.ShowAll, "show-all", config.ShowAll, "Show all alerts in detailed format")
+	fs.BoolVar(&config.ShowAll, "a", config.ShowAll, "Show all alerts in detailed format")
+	fs.IntVar(&config.Top, "top", config.Top, "Number of top alerts to show, 0 for all")
+	fs.StringVar(&config.Trends, "trends", config.Trends, "Show hourly trends instead of alerts, as text or json")
+	fs.DurationVar(&config.TrendsWindow, "trends-window", config.TrendsWindow, "Window for trends, ending with the latest alert")
+}
+
+// registerTimeFlags defines the flags of how times are rendered
+func registerTimeFlags(fs *flag.FlagSet, config *Config) {
+	fs.StringVar(&config.TZ, "tz", config.TZ, "Render times in a timezone: an IANA name such as Europe/Helsinki, local or UTC")
+	fs.BoolVar(&config.Relative, "relative", config.Relative, "Also render times relative to now, e.g. 12m ago")
+}
+
+// registerAnalysisFlags defines the flags that control how alerts are
+// filtered, annotated and scored before they are shown
+func registerAnalysisFlags(fs *flag.FlagSet, config *Config) {
+	fs.IntVar(&config.LastMinutes, "lastminutes", config.LastMinutes, "Filter alerts from the last N minutes")
+	fs.StringVar(&config.MinSeverity, "min-severity", config.MinSeverity, "Only keep alerts at or above a severity, e.g. warning")
+	fs.StringVar(&config.Filter, "filter", config.Filter, "Only keep alerts whose fields match glob patterns, e.g. labels.region=eu-*,severity=critical|warning")
+	fs.StringVar(&config.Sort, "sort", config.Sort, "Sort alerts by comma-separated field:asc|desc keys, e.g. priority:desc,timestamp:asc")
+	fs.StringVar(&config.MaintenanceFile, "maintenance", config.MaintenanceFile, "JSON file with maintenance windows and silences")
+	fs.StringVar(&config.RoutesFile, "routes", config.RoutesFile, "JSON file with the routing tree, to annotate alerts with their team")
+	fs.StringVar(&config.OnCallFile, "oncall", config.OnCallFile, "JSON file with on-call schedules, to annotate alerts with who was on call")
+	fs.BoolVar(&config.Detect, "detect", config.Detect, "Detect flapping alerts and alert storms")
+	fs.StringVar(&config.Detected, "detected", config.Detected, "Only show alerts flagged by a detector (flapping, storm); implies --detect")
+	fs.DurationVar(&config.detectors.FlapWindow, "flap-window", config.detectors.FlapWindow, "Window for counting state changes of the same alert")
+	fs.IntVar(&config.detectors.FlapThreshold, "flap-threshold", config.detectors.FlapThreshold, "State changes within the flap window that count as flapping")
+	fs.DurationVar(&config.detectors.StormWindow, "storm-window", config.detectors.StormWindow, "Window for measuring alert rates per service")
+	fs.Float64Var(&config.detectors.StormFactor, "storm-factor", config.detectors.StormFactor, "How many times the service's baseline rate counts as a storm")
+	fs.StringVar(&config.HistoryFiles, "history", config.HistoryFiles, "Comma-separated JSON files with past alerts, for anomaly scoring")
+	fs.StringVar(&config.Anomaly, "anomaly", config.Anomaly, "Anomaly scoring method (zscore, mad, ewma)")
+	fs.Float64Var(&config.weights.Severity, "severity-weight", config.weights.Severity, "Weight of the severity score in the priority")
+	fs.Float64Var(&config.weights.Deviation, "deviation-weight", config.weights.Deviation, "Weight of the deviation from threshold (in %) in the priority")
+	fs.Float64Var(&config.weights.Components, "components-weight", config.weights.Components, "Weight of the number of affected components in the priority")
+	fs.Float64Var(&config.weights.Anomaly, "anomaly-weight", config.weights.Anomaly, "Weight of the anomaly score in the priority")
+	fs.StringVar(&config.BlastRadius, "blast-radius", config.BlastRadius, "Count affected components among the filtered alerts or all loaded alerts (filtered, all)")
+}
+
+// registerValidationFlags defines the flags of input validation
+func registerValidationFlags(fs *flag.FlagSet, config *Config) {
+	fs.BoolVar(&config.Strict, "strict", config.Strict, "Validate the input first and refuse it if any rule at error level fails")
+	fs.BoolVar(&config.Schema, "schema", config.Schema, "Check the input against the JSON Schema first and refuse it on mismatches")
+	fs.StringVar(&config.Rules, "rules", config.Rules, "Validation levels per rule, e.g. zero-threshold=error,duplicate-id=off")
+}
+
+// registerRankingFlags defines the flags of the summary rankings
+func registerRankingFlags(fs *flag.FlagSet, config *Config) {
+	fs.IntVar(&config.RankTop, "rank-top", config.RankTop, "Number of services, components and metrics in the summary rankings")
+	fs.StringVar(&config.RankBy, "rank-by", config.RankBy, "Rank the summary by alert count, total priority or critical alerts (count, priority, critical or all; comma-separated)")
+}
+
+// registerNotifierFlags defines where and how notify sends alerts
+func registerNotifierFlags(fs *flag.FlagSet, config *Config) {
+	fs.StringVar(&config.notifier.Type, "type", config.notifier.Type, "Notifier type (slack, webhook)")
+	fs.StringVar(&config.notifier.Webhook, "webhook", config.notifier.Webhook, "Webhook URL to post to")
+	fs.StringVar(&config.notifier.Template, "template", config.notifier.Template, "Go text/template file for the message text")
+	fs.StringVar(&config.notifier.State, "state", config.notifier.State, "State file; only alerts not sent in earlier runs are sent")
+	fs.IntVar(&config.notifier.Retries, "retries", config.notifier.Retries, "Number of retries for failed requests")
+	fs.DurationVar(&config.notifier.Backoff, "backoff", config.notifier.Backoff, "Initial retry delay, doubled after each retry")
+}
+
+// registerMailFlags defines how digest sends its email
+func registerMailFlags(fs *flag.FlagSet, config *Config) {
+	fs.StringVar(&config.mail.From, "from", config.mail.From, "Sender address")
+	fs.StringVar(&config.mail.To, "to", config.mail.To, "Comma-separated recipient addresses")
+	fs.StringVar(&config.mail.Subject, "subject", config.mail.Subject, "Email subject")
+	fs.StringVar(&config.mail.SMTP, "smtp", config.mail.SMTP, "SMTP server address (host:port) to send the digest through")
+	fs.StringVar(&config.mail.SMTPUser, "smtp-user", config.mail.SMTPUser, "SMTP username; the password is read from ENC_ALERTBUDDY_SMTP_PASSWORD")
 }
 
 func parseFlags() (*Config, error) {
-	config := &Config{}
+	config := newConfig()
 	
 	// Define flags
-	flag.StringVar(&config.InputFile, "i", "", "Input JSON file containing alerts")
-	flag.StringVar(&config.InputFile, "input", "", "Input JSON file containing alerts")
-	flag.StringVar(&config.GroupBy, "groupby", "", "Group alerts by field (severity, service, component, metric, etc.)")
-	flag.IntVar(&config.LastMinutes, "lastminutes", 0, "Filter alerts from the last N minutes")
-	flag.BoolVar(&config.ShowAll, "show-all", false, "Show all alerts in detailed format")
-	flag.BoolVar(&config.ShowAll, "a", false, "Show all alerts in detailed format")
+	registerInputFlags(flag.CommandLine, config)
+	registerDisplayFlags(flag.CommandLine, config)
+	registerTimeFlags(flag.CommandLine, config)
+	registerAnalysisFlags(flag.CommandLine, config)
+	registerRankingFlags(flag.CommandLine, config)
+	registerValidationFlags(flag.CommandLine, config)
 	flag.BoolVar(&config.ShowVersion, "version", false, "Show version information")
 	flag.BoolVar(&config.ShowVersion, "v", false, "Show version information")
 	flag.BoolVar(&config.ShowHelp, "help", false, "Show help information")
@@ -42,7 +224,10 @@ func parseFlags() (*Config, error) {
 		showHelp()
 	}
 	
-	// Parse flags
+	// Apply the config file and environment, then parse flags over them
+	if err := applySettings(flag.CommandLine, os.Args[1:]); err != nil {
+		return nil, err
+	}
 	flag.Parse()
 	
 	// Handle version
@@ -57,31 +242,234 @@ func parseFlags() (*Config, error) {
 		os.Exit(0)
 	}
 	
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// validate checks a parsed configuration, whichever command it came from
+func (config *Config) validate() error {
 	// Validate required flags
 	if config.InputFile == "" {
-		return nil, fmt.Errorf("input file is required. Use -i or --input to specify the JSON file")
+		return fmt.Errorf("input file is required. Use -i or --input to specify the JSON file")
 	}
 	
 	// Check if file exists
 	if _, err := os.Stat(config.InputFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("input file '%s' does not exist", config.InputFile)
+		return fmt.Errorf("input file '%s' does not exist", config.InputFile)
+	}
+	
+	if config.MaintenanceFile != "" {
+		if _, err := os.Stat(config.MaintenanceFile); os.IsNotExist(err) {
+			return fmt.Errorf("maintenance file '%s' does not exist", config.MaintenanceFile)
+		}
+	}
+	
+	for _, file := range []string{config.RoutesFile, config.OnCallFile} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			return fmt.Errorf("file '%s' does not exist", file)
+		}
+	}
+	
+	for _, file := range splitList(config.HistoryFiles) {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			return fmt.Errorf("history file '%s' does not exist", file)
+		}
+	}
+	
+	if config.OnCallFile != "" && config.RoutesFile == "" {
+		return fmt.Errorf("on-call schedules need a routing tree. Use --routes to specify it")
 	}
 	
 	// Validate groupby field if provided
 	if config.GroupBy != "" {
-		validFields := []string{"severity", "service", "component", "metric", "threshold", "value", "priority"}
-		if !contains(validFields, strings.ToLower(config.GroupBy)) {
-			return nil, fmt.Errorf("invalid groupby field '%s'. Valid fields: %s", 
-				config.GroupBy, strings.Join(validFields, ", "))
+		if !isGroupableField(config.GroupBy) {
+			return fmt.Errorf("invalid groupby field '%s'. Valid fields: %s", 
+				config.GroupBy, groupableFieldsHelp())
 		}
 	}
 	
+	// Validate detector flags
+	if config.Detected != "" {
+		if config.Detected != "flapping" && config.Detected != "storm" {
+			return fmt.Errorf("invalid detected filter '%s'. Valid filters: flapping, storm", config.Detected)
+		}
+		config.Detect = true
+	}
+	if config.detectors.FlapWindow <= 0 || config.detectors.StormWindow <= 0 ||
+		config.detectors.FlapThreshold <= 0 || config.detectors.StormFactor <= 0 {
+		return fmt.Errorf("detector windows and thresholds must be positive")
+	}
+	
+	// Validate anomaly scoring flags
+	if !contains(anomalyMethods, config.Anomaly) {
+		return fmt.Errorf("invalid anomaly method '%s'. Valid methods: %s",
+			config.Anomaly, strings.Join(anomalyMethods, ", "))
+	}
+	config.Anomaly = strings.ToLower(config.Anomaly)
+	if config.weights.Severity < 0 || config.weights.Deviation < 0 ||
+		config.weights.Components < 0 || config.weights.Anomaly < 0 {
+		return fmt.Errorf("priority weights must not be negative")
+	}
+	
+	// Validate trends flags
+	if config.Trends != "" && config.Trends != "text" && config.Trends != "json" {
+		return fmt.Errorf("invalid trends format '%s'. Valid formats: text, json", config.Trends)
+	}
+	if config.TrendsWindow < time.Hour {
+		return fmt.Errorf("trends window must be at least 1h")
+	}
+	
+	// Validate ranking flags
+	rankMetrics, err := parseRankMetrics(config.RankBy)
+	if err != nil {
+		return err
+	}
+	config.rankMetrics = rankMetrics
+	if config.RankTop <= 0 {
+		return fmt.Errorf("rank-top must be a positive number")
+	}
+	
+	// Validate output flags
+	if config.Format != "text" && config.Format != "json" {
+		return fmt.Errorf("invalid format '%s'. Valid formats: text, json", config.Format)
+	}
+	if config.Top < 0 {
+		return fmt.Errorf("top must not be negative. Use 0 to show all alerts")
+	}
+	
+	// Validate sort keys
+	if config.Sort == "" {
+		config.Sort = defaultSortSpec
+	}
+	sortKeys, err := parseSortKeys(config.Sort)
+	if err != nil {
+		return err
+	}
+	config.sortKeys = sortKeys
+	
+	if config.BlastRadius == "" {
+		config.BlastRadius = BlastRadiusFiltered
+	}
+	if !contains(blastRadiusScopes, config.BlastRadius) {
+		return fmt.Errorf("invalid blast radius '%s'. Valid values: %s",
+			config.BlastRadius, strings.Join(blastRadiusScopes, ", "))
+	}
+	
+	if config.Schema && config.MappingFile != "" {
+		return fmt.Errorf("--schema checks the native input format and can't be combined with --mapping")
+	}
+	
+	// Validate time flags
+	location, err := parseTimezone(config.TZ)
+	if err != nil {
+		return err
+	}
+	config.display = TimeDisplay{Location: location, Relative: config.Relative}
+	
+	// Validate directory patterns
+	for _, pattern := range append(splitList(config.Include), splitList(config.Exclude)...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid file pattern '%s': %v", pattern, err)
+		}
+	}
+	
+	// Validate field filters
+	filters, err := parseFieldFilters(config.Filter)
+	if err != nil {
+		return err
+	}
+	config.filters = filters
+	
+	// Validate validation rules
+	levels, err := parseValidationLevels(config.Rules)
+	if err != nil {
+		return err
+	}
+	config.ruleLevels = levels
+	
 	// Validate lastminutes if provided
 	if config.LastMinutes < 0 {
-		return nil, fmt.Errorf("lastminutes must be a positive number")
+		return fmt.Errorf("lastminutes must be a positive number")
 	}
 	
-	return config, nil
+	return nil
+}
+
+// load reads the auxiliary files the configuration refers to
+func (config *Config) load() error {
+	var err error
+	if config.SeveritiesFile != "" {
+		if config.severities, err = loadSeverityModel(config.SeveritiesFile); err != nil {
+			return err
+		}
+	}
+	if config.MinSeverity != "" {
+		if _, ok := config.severities.Rank(config.MinSeverity); !ok {
+			return fmt.Errorf("invalid min-severity '%s'. Valid severities: %s",
+				config.MinSeverity, strings.Join(config.severities.Names(), ", "))
+		}
+	}
+	
+	if config.MappingFile != "" {
+		if config.mapping, err = loadFieldMapping(config.MappingFile, config.severities); err != nil {
+			return err
+		}
+	}
+	
+	if config.MaintenanceFile != "" {
+		if config.maintenanceWindows, err = loadMaintenanceWindows(config.MaintenanceFile); err != nil {
+			return err
+		}
+	}
+	
+	if config.RoutesFile != "" {
+		if config.routes, err = loadRoutingConfig(config.RoutesFile); err != nil {
+			return err
+		}
+	}
+	
+	if config.OnCallFile != "" {
+		if config.schedules, err = loadOnCallSchedules(config.OnCallFile); err != nil {
+			return err
+		}
+	}
+	
+	if config.HistoryFiles != "" {
+		if config.history, err = loadAnomalyHistory(splitList(config.HistoryFiles), config.loadOptions()); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// loadOptions returns how input files are read
+func (config *Config) loadOptions() LoadOptions {
+	return LoadOptions{
+		Mapping:    config.mapping,
+		Severities: config.severities,
+		Recursive:  config.Recursive,
+		Include:    splitList(config.Include),
+		Exclude:    splitList(config.Exclude),
+		Reports:    os.Stderr,
+	}
+}
+
+// reportWriter returns where progress messages and reports go. They move to
+// stderr when the output is JSON or a file, so they never mix with it.
+func (config *Config) reportWriter() io.Writer {
+	if config.reports != nil {
+		return config.reports
+	}
+	if config.Format == "json" || config.OutputFile != "" {
+		return os.Stderr
+	}
+	return os.Stdout
 }
 
 func showVersion() {
@@ -92,15 +480,76 @@ func showVersion() {
 func showHelp() {
 	fmt.Printf("%s - Alert Management CLI Tool\n\n", AppName)
 	fmt.Println("USAGE:")
+	fmt.Printf("  %s <subcommand> -i <input-file> [OPTIONS]\n", AppName)
 	fmt.Printf("  %s -i <input-file> [OPTIONS]\n\n", AppName)
 	
+	fmt.Println("SUBCOMMANDS:")
+	fmt.Println("  top                    Show the highest priority alerts and summary statistics")
+	fmt.Println("  list                   Show all alerts in detailed format")
+	fmt.Println("  group                  Group alerts by a field")
+	fmt.Println("  stats                  Show summary statistics, rankings and hourly trends")
+	fmt.Println("  diff                   Compare two exports: new, resolved and ongoing alerts")
+	fmt.Println("  serve                  Serve alerts as JSON over HTTP")
+	fmt.Println("  validate               Check an alerts file and exit non-zero on errors")
+	fmt.Println("  schema                 Print the JSON Schema of input files")
+	fmt.Println("  config show            Print the effective configuration and where each value comes from")
+	fmt.Println("  route                  Show which team each alert is routed to")
+	fmt.Println("  notify                 Send top alerts to a Slack or generic JSON webhook")
+	fmt.Println("  digest                 Render a digest email and send it via SMTP or write a .eml file")
+	fmt.Println("  export                 Export high priority alerts as PagerDuty or Opsgenie events")
+	fmt.Println("  simulate-escalation    Replay alerts against an escalation policy")
+	fmt.Println("  generate               Generate a synthetic alerts file for benchmarks")
+	fmt.Printf("\n  Run '%s <subcommand> -h' for subcommand flags. Most subcommands take\n", AppName)
+	fmt.Println("  -o/--output <file> and --format text|json. Without a subcommand, the flags")
+	fmt.Println("  below select what is shown: -i alone is top, -a is list, --groupby is group")
+	fmt.Println("  and --trends is stats.")
+	fmt.Println()
+	
 	fmt.Println("REQUIRED FLAGS:")
-	fmt.Println("  -i, --input <file>     Input JSON file containing alerts")
+	fmt.Println("  -i, --input <file>     Input JSON file containing alerts, or a directory of them")
 	
 	fmt.Println("\nOPTIONAL FLAGS:")
-	fmt.Println("  --groupby <field>      Group alerts by field (severity, service, component, metric, threshold, value, priority)")
+	fmt.Println("  --groupby <field>      Group alerts by field (severity, service, component, metric, threshold, value, priority,")
+	fmt.Println("                         labels.<name>, annotations.<name>)")
 	fmt.Println("  --lastminutes <n>      Filter alerts from the last N minutes")
+	fmt.Println("  --mapping <file>       JSON file mapping a foreign alert format to alert fields")
+	fmt.Println("  --severities <file>    JSON file with a custom severity ladder, aliases and scores")
+	fmt.Println("  --recursive            Read the subdirectories of an input directory too")
+	fmt.Println("  --include <globs>      Files read from an input directory (default *.json, *.json.*, *.tar, *.tar.*, *.tgz)")
+	fmt.Println("  --exclude <globs>      Files skipped in an input directory")
+	fmt.Println("  --min-severity <sev>   Only keep alerts at or above a severity, e.g. warning")
+	fmt.Println("  --filter <matchers>    Only keep alerts whose fields match glob patterns, e.g. labels.region=eu-*")
 	fmt.Println("  --show-all, -a         Show all alerts in detailed format")
+	fmt.Println("  --top <n>              Number of top alerts to show, 0 for all (default 10)")
+	fmt.Println("  --sort <keys>          Sort by field:asc|desc keys, e.g. severity,timestamp:asc (default priority:desc)")
+	fmt.Println("  --maintenance <file>   Suppress or down-weight alerts inside maintenance windows")
+	fmt.Println("  --routes <file>        Annotate alerts with the team they are routed to")
+	fmt.Println("  --oncall <file>        Annotate alerts with who was on call (requires --routes)")
+	fmt.Println("  --detect               Detect flapping alerts and alert storms")
+	fmt.Println("  --detected <kind>      Only show alerts flagged as flapping or storm (implies --detect)")
+	fmt.Println("  --flap-window <d>      Window for counting state changes (default 1h)")
+	fmt.Println("  --flap-threshold <n>   State changes within the window that count as flapping (default 4)")
+	fmt.Println("  --storm-window <d>     Window for measuring alert rates per service (default 10m)")
+	fmt.Println("  --storm-factor <x>     Rate above the service baseline that counts as a storm (default 3)")
+	fmt.Println("  --history <files>      Comma-separated past alert exports to score anomalies against")
+	fmt.Println("  --anomaly <method>     Anomaly scoring method: zscore, mad or ewma (default zscore)")
+	fmt.Println("  --severity-weight <x>  Weight of the severity score in the priority (default 1)")
+	fmt.Println("  --deviation-weight <x> Weight of the deviation from threshold in % (default 0.1)")
+	fmt.Println("  --components-weight <x> Weight of the number of affected components (default 2)")
+	fmt.Println("  --anomaly-weight <x>   Weight of the anomaly score in the priority (default 1)")
+	fmt.Println("  --blast-radius <scope> Count affected components among the filtered or all loaded alerts (default filtered)")
+	fmt.Println("  --trends <format>      Show hourly trends and a comparison to the previous window (text, json)")
+	fmt.Println("  --trends-window <d>    Window for trends, ending with the latest alert (default 24h)")
+	fmt.Println("  --tz <zone>            Render times in a timezone, e.g. Europe/Helsinki or local")
+	fmt.Println("  --relative             Also render times relative to now, e.g. 12m ago")
+	fmt.Println("  --rank-top <n>         Entries in the summary rankings of services, components and metrics (default 5)")
+	fmt.Println("  --rank-by <metrics>    Rank the summary by count, priority, critical or all (default count).")
+	fmt.Println("                         Every entry shows all three totals; the metric sets the order")
+	fmt.Println("  --strict               Validate the input first and refuse it on errors")
+	fmt.Println("  --schema               Check the input against the JSON Schema first")
+	fmt.Println("  --rules <levels>       Validation level per rule, e.g. zero-threshold=error,duplicate-id=off")
+	fmt.Println("  --config <file>        YAML config file (default ~/.config/enc-alertbuddy/config.yaml)")
+	fmt.Println("  --profile <name>       Use a named profile from the config file")
 	fmt.Println("  -v, --version          Show version information")
 	fmt.Println("  -h, --help             Show this help message")
 	
@@ -112,7 +561,28 @@ func showHelp() {
 	fmt.Printf("  %s -i alerts.json --groupby severity\n", AppName)
 	fmt.Printf("  %s -i alerts.json --lastminutes=30\n", AppName)
 	fmt.Printf("  %s -i alerts.json --groupby=service --lastminutes=60\n", AppName)
+	fmt.Printf("  %s -i alerts.json --min-severity=warning\n", AppName)
+	fmt.Printf("  %s -i alerts.json --top=20 --sort=severity,timestamp:asc\n", AppName)
+	fmt.Printf("  %s -i alerts.json --groupby=labels.region --filter=labels.cluster=prod-*\n", AppName)
 	fmt.Printf("  %s -i alerts.json --show-all --lastminutes=30\n", AppName)
+	fmt.Printf("  %s -i alerts.json --maintenance=windows.json\n", AppName)
+	fmt.Printf("  %s -i alerts.json --routes=routes.json --oncall=schedules.json\n", AppName)
+	fmt.Printf("  %s -i alerts.json --detected=flapping\n", AppName)
+	fmt.Printf("  %s -i today.json --history=monday.json,tuesday.json --anomaly=mad\n", AppName)
+	fmt.Printf("  %s -i alerts.json --trends=text --trends-window=12h\n", AppName)
+	fmt.Printf("  %s -i alerts.json --rank-by=priority --rank-top=10\n", AppName)
+	fmt.Printf("  %s top -i alerts.json -n 5 --format=json\n", AppName)
+	fmt.Printf("  %s group -i alerts.json --by=service -o groups.txt\n", AppName)
+	fmt.Printf("  %s diff -i today.json --previous=yesterday.json\n", AppName)
+	fmt.Printf("  %s serve -i alerts.json --addr=:8080\n", AppName)
+	fmt.Printf("  %s validate -i alerts.json --rules=zero-threshold=error\n", AppName)
+	fmt.Printf("  %s --profile=oncall\n", AppName)
+	fmt.Printf("  %s config show --profile=weekly-report\n", AppName)
+	fmt.Printf("  %s route -i alerts.json --routes=routes.json --top=3\n", AppName)
+	fmt.Printf("  %s notify -i alerts.json --webhook=$SLACK_URL --dry-run=payloads.jsonl\n", AppName)
+	fmt.Printf("  %s digest -i today.json --previous=yesterday.json -o digest.eml\n", AppName)
+	fmt.Printf("  %s export -i alerts.json --provider=pagerduty --min-priority=60 -o events.jsonl\n", AppName)
+	fmt.Printf("  %s simulate-escalation -i alerts.json --policy=policy.json\n", AppName)
 	
 	fmt.Println("\nVALID GROUPBY FIELDS:")
 	fmt.Println("  severity    - Group by alert severity (critical, warning, info)")
@@ -126,10 +596,16 @@ func showHelp() {
 	fmt.Println("\nFEATURES:")
 	fmt.Println("  • Automatic priority calculation based on severity, deviation, and affected components")
 	fmt.Println("  • Time-based filtering to focus on recent alerts")
+	fmt.Println("  • Recurring maintenance windows and one-off silences")
+	fmt.Println("  • Flapping and alert storm detection")
+	fmt.Println("  • Anomaly scoring against each component's own history")
+	fmt.Println("  • Hourly trends with sparklines and a comparison to the previous window")
+	fmt.Println("  • Config file with named profiles")
 	fmt.Println("  • Flexible grouping by any alert field")
 	fmt.Println("  • Show all alerts in detailed format with --show-all")
 	fmt.Println("  • Beautiful formatted output for better readability")
 	fmt.Println("  • Support for large alert datasets")
+	fmt.Println("  • gzip, zstd and tar archives read without unpacking")
 }
 
 func contains(slice []string, item string) bool {
@@ -141,44 +617,130 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
-func loadAlertsFromFile(filename string) (*Alerts, error) {
-	// Read the JSON file
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("error reading file '%s': %v", filename, err)
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadAlertsFromFile reads an alerts file, converting it with the field
+// mapping of the load options, if any, and normalizes its severities. The
+// file may be compressed with gzip or zstd, or be a tar archive of alerts
+// files, whose alerts are merged. A directory is read file by file; see
+// loadAlertsFromDirectory.
+func loadAlertsFromFile(filename string, options ...LoadOptions) (*Alerts, error) {
+	option := mergeLoadOptions(options)
+	if info, err := os.Stat(filename); err == nil && info.IsDir() {
+		return loadAlertsFromDirectory(filename, option)
 	}
 	
-	// Unmarshal JSON into alerts struct
-	var alerts Alerts
-	err = json.Unmarshal(data, &alerts)
+	alerts, err := readAlerts(filename, option)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing JSON from '%s': %v", filename, err)
+		return nil, err
 	}
 	
 	if len(alerts.Alerts) == 0 {
 		return nil, fmt.Errorf("no alerts found in file '%s'", filename)
 	}
 	
-	return &alerts, nil
+	alerts.NormalizeSeverities(option.Severities)
+	return alerts, nil
 }
 
-func processAlerts(alerts *Alerts, config *Config) {
-	// Calculate priorities for all alerts
-	alerts.CalculateAllPriorities()
-	
-	// Sort by priority (highest first)
-	alerts.SortByPriority()
+// readAlerts reads the alerts of every JSON document of a file
+func readAlerts(filename string, option LoadOptions) (*Alerts, error) {
+	// Read the JSON documents of the file
+	documents, err := readInputDocuments(filename)
+	if err != nil {
+		return nil, err
+	}
 	
-	// Apply time filter if specified
-	if config.LastMinutes > 0 {
-		fmt.Printf("🕒 Filtering alerts from the last %d minutes...\n", config.LastMinutes)
-		filtered := alerts.FilterByLastMinutes(config.LastMinutes)
-		alerts = &filtered
+	var alerts Alerts
+	for _, document := range documents {
+		data := document.Data
+		if option.Mapping != nil {
+			if data, err = option.Mapping.Apply(data); err != nil {
+				return nil, fmt.Errorf("error mapping alerts from '%s': %v", document.Name, err)
+			}
+		}
 		
-		if len(alerts.Alerts) == 0 {
-			fmt.Printf("⚠️  No alerts found in the last %d minutes.\n", config.LastMinutes)
-			return
+		// Unmarshal JSON into alerts struct
+		var parsed Alerts
+		err = json.Unmarshal(data, &parsed)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing JSON from '%s': %v", document.Name, err)
 		}
+		alerts.Alerts = append(alerts.Alerts, parsed.Alerts...)
+	}
+	return &alerts, nil
+}
+
+// pipeline returns the stages the configuration asks for. The time window
+// is applied before scoring, so alerts outside it don't add to the blast
+// radius of others unless --blast-radius=all.
+func (config *Config) pipeline() *Pipeline {
+	pipeline := NewPipeline()
+	pipeline.Reports = config.reportWriter()
+	
+	if config.display.Location != nil {
+		pipeline.With(ZoneStage(config.display.Location))
+	}
+	if len(config.filters) > 0 {
+		pipeline.With(FieldFilterStage(config.filters))
+	}
+	if config.MinSeverity != "" {
+		pipeline.With(MinSeverityStage(config.severities, config.MinSeverity))
+	}
+	// Maintenance comes before scoring so suppressed alerts don't count
+	// towards the blast radius of others
+	if len(config.maintenanceWindows) > 0 {
+		pipeline.With(MaintenanceStage(config.maintenanceWindows))
+	}
+	if config.Detect {
+		pipeline.With(DetectionStage(config.detectors, config.Detected))
+	}
+	if config.LastMinutes > 0 {
+		pipeline.With(TimeWindowStage(config.LastMinutes))
+	}
+	// Anomaly scores feed into the priority
+	if config.history != nil {
+		pipeline.With(AnomalyStage(config.history, config.Anomaly))
+	}
+	if config.routes != nil {
+		pipeline.With(OnCallStage(config.routes, config.schedules))
+	}
+	return pipeline.With(
+		ScoreStage(config.weights, config.BlastRadius),
+		SortStage(config.sortKeys, config.severities),
+	)
+}
+
+// processAlerts runs the pipeline and shows the alerts left. It only fails
+// if the output can't be written.
+func processAlerts(input *Alerts, config *Config) error {
+	pipeline := config.pipeline()
+	switch {
+	case config.Trends != "":
+		// Trends cover their own window, so they ignore the time filter
+		pipeline.Without("time-window")
+	case config.GroupBy == "" && !config.ShowAll:
+		// Only the top alerts are shown
+		pipeline.With(TopStage(config.Top, config.sortKeys, config.severities))
+	}
+	
+	prepared := pipeline.Run(*input)
+	if len(prepared.Alerts) == 0 {
+		return nil
+	}
+	alerts := &prepared
+	
+	if config.Trends != "" {
+		return showTrends(os.Stdout, buildTrends(*alerts, config.TrendsWindow, config.display.Location), config.Trends)
 	}
 	
 	// Show summary
@@ -191,82 +753,103 @@ func processAlerts(alerts *Alerts, config *Config) {
 	// Group and display if groupby is specified
 	if config.GroupBy != "" {
 		fmt.Printf("📋 Grouping alerts by: %s\n", config.GroupBy)
-		alerts.PrettyPrintGroupedBy(config.GroupBy)
+		alerts.PrettyPrintGroupedBy(os.Stdout, config.GroupBy)
 	} else if config.ShowAll {
 		// Show all alerts in detailed format
 		fmt.Printf("📋 Showing all %d alerts in detailed format:\n", len(alerts.Alerts))
-		alerts.PrettyPrint()
+		alerts.PrettyPrintWith(os.Stdout, config.display)
+	} else {
+		// Show the highest priority alerts and summary statistics
+		showTopAlerts(os.Stdout, alerts, config)
+		showSummaryStats(os.Stdout, alerts, config)
+	}
+	return nil
+}
+
+// showTopAlerts prints the first --top alerts, which must be sorted, or all
+// of them if it is 0
+func showTopAlerts(w io.Writer, alerts *Alerts, config *Config) {
+	maxDisplay := config.Top
+	if maxDisplay <= 0 || len(alerts.Alerts) < maxDisplay {
+		maxDisplay = len(alerts.Alerts)
+	}
+	
+	if config.Sort == "" || config.Sort == defaultSortSpec {
+		fmt.Fprintf(w, "🔥 Top %d Highest Priority Alerts:\n", maxDisplay)
 	} else {
-		// Show top 10 highest priority alerts
-		maxDisplay := 10
-		if len(alerts.Alerts) < maxDisplay {
-			maxDisplay = len(alerts.Alerts)
+		fmt.Fprintf(w, "🔥 Top %d Alerts by %s:\n", maxDisplay, config.Sort)
+	}
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	
+	for i := 0; i < maxDisplay; i++ {
+		alert := alerts.Alerts[i]
+		fmt.Fprintf(w, "\n[%d] Priority: %.2f | %s | %s\n", 
+			i+1, alert.Priority, alert.Severity, alert.ID)
+		fmt.Fprintf(w, "    Service: %s | Component: %s\n", 
+			alert.Service, alert.Component)
+		fmt.Fprintf(w, "    Metric: %s (%.2f / %.2f)\n", 
+			alert.Metric, alert.Value, alert.Threshold)
+		fmt.Fprintf(w, "    Description: %s\n", alert.Description)
+		if alert.Maintenance != "" {
+			fmt.Fprintf(w, "    Maintenance: %s (down-weighted)\n", alert.Maintenance)
 		}
-		
-		fmt.Printf("🔥 Top %d Highest Priority Alerts:\n", maxDisplay)
-		fmt.Println(strings.Repeat("=", 60))
-		
-		for i := 0; i < maxDisplay; i++ {
-			alert := alerts.Alerts[i]
-			fmt.Printf("\n[%d] Priority: %.2f | %s | %s\n", 
-				i+1, alert.Priority, alert.Severity, alert.ID)
-			fmt.Printf("    Service: %s | Component: %s\n", 
-				alert.Service, alert.Component)
-			fmt.Printf("    Metric: %s (%.2f / %.2f)\n", 
-				alert.Metric, alert.Value, alert.Threshold)
-			fmt.Printf("    Description: %s\n", alert.Description)
+		if alert.Team != "" {
+			fmt.Fprintf(w, "    Team: %s | On call: %s\n", alert.Team, onCallOrUnknown(alert.OnCall))
 		}
-		
-		if len(alerts.Alerts) > maxDisplay {
-			fmt.Printf("\n... and %d more alerts (use --show-all to see all or --groupby to organize)\n", 
-				len(alerts.Alerts)-maxDisplay)
+		if alert.Flapping {
+			fmt.Fprintf(w, "    🔁 Flapping: fired %d times\n", alert.FlapCount)
+		}
+		if alert.Storm {
+			fmt.Fprintln(w, "    ⛈️  Part of an alert storm")
+		}
+		if alert.AnomalyScore > 0 {
+			fmt.Fprintf(w, "    📐 Anomaly score: %.2f\n", alert.AnomalyScore)
 		}
-		
-		// Show summary statistics
-		showSummaryStats(alerts)
+	}
+	
+	if len(alerts.Alerts) > maxDisplay {
+		fmt.Fprintf(w, "\n... and %d more alerts (use --show-all to see all or --groupby to organize)\n", 
+			len(alerts.Alerts)-maxDisplay)
 	}
 }
 
-func showSummaryStats(alerts *Alerts) {
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("📈 SUMMARY STATISTICS")
-	fmt.Println(strings.Repeat("=", 60))
-	
-	// Count by severity
-	severityCounts := make(map[string]int)
-	serviceCounts := make(map[string]int)
-	var totalPriority float64
+func showSummaryStats(w io.Writer, alerts *Alerts, config *Config) {
+	fmt.Fprintln(w, "\n" + strings.Repeat("=", 60))
+	fmt.Fprintln(w, "📈 SUMMARY STATISTICS")
+	fmt.Fprintln(w, strings.Repeat("=", 60))
 	
-	for _, alert := range alerts.Alerts {
-		severityCounts[alert.Severity]++
-		serviceCounts[alert.Service]++
-		totalPriority += alert.Priority
-	}
+	stats := summarizeAlerts(*alerts)
 	
 	// Severity breakdown
-	fmt.Println("\n🚨 Severity Breakdown:")
-	for _, severity := range []string{"critical", "warning", "info"} {
-		if count, exists := severityCounts[severity]; exists {
-			percentage := float64(count) / float64(len(alerts.Alerts)) * 100
-			fmt.Printf("  %s: %d alerts (%.1f%%)\n", 
-				strings.Title(severity), count, percentage)
-		}
+	fmt.Fprintln(w, "\n🚨 Severity Breakdown:")
+	severities := config.severities
+	if severities == nil {
+		severities = defaultSeverities
+	}
+	for _, entry := range severityBreakdown(severities, stats.SeverityCounts) {
+		percentage := float64(entry.Count) / float64(stats.Total) * 100
+		fmt.Fprintf(w, "  %s: %d alerts (%.1f%%)\n", 
+			strings.Title(entry.Name), entry.Count, percentage)
 	}
 	
 	// Average priority
-	avgPriority := totalPriority / float64(len(alerts.Alerts))
-	fmt.Printf("\n⚡ Average Priority Score: %.2f\n", avgPriority)
-	
-	// Top services
-	fmt.Printf("\n🏢 Top 5 Services by Alert Count:\n")
-	// Simple approach - show first 5 services
-	count := 0
-	for service, alertCount := range serviceCounts {
-		if count < 5 {
-			fmt.Printf("  %s: %d alerts\n", service, alertCount)
-			count++
+	fmt.Fprintf(w, "\n⚡ Average Priority Score: %.2f\n", stats.AveragePriority)
+	
+	// Top services, components and metrics
+	rankMetrics, rankTop := config.rankMetrics, config.RankTop
+	if len(rankMetrics) == 0 {
+		rankMetrics = []string{"count"}
+	}
+	if rankTop <= 0 {
+		rankTop = 5
+	}
+	for _, dimension := range rankingDimensions {
+		for _, metric := range rankMetrics {
+			showRanking(w, alerts.Rank(dimension, metric, rankTop), rankTop)
 		}
 	}
+	
+	showOnCallLoad(w, alerts)
 }
 
 func handleCLIError(err error) {
@@ -276,18 +859,46 @@ func handleCLIError(err error) {
 }
 
 func runCLI() {
+	if len(os.Args) > 1 {
+		if command, ok := subcommands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	
 	config, err := parseFlags()
 	if err != nil {
 		handleCLIError(err)
 	}
 	
+	if err := config.load(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+	
+	if err := checkSchema(config); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+	
 	// Load alerts from file
-	alerts, err := loadAlertsFromFile(config.InputFile)
+	alerts, err := loadAlertsFromFile(config.InputFile, config.loadOptions())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
 		os.Exit(1)
 	}
 	
+	if err := checkStrict(*alerts, config); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+	
 	// Process and display alerts
-	processAlerts(alerts, config)
+	if err := processAlerts(alerts, config); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
 }
\ No newline at end of file
@@ -387,16 +387,22 @@ func TestProcessAlerts_Integration(t *testing.T) {
 	
 	// This should not panic or error
 	// Note: In real tests, you might want to capture output
-	processAlerts(alerts, config)
+	if err := processAlerts(alerts, config); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
 	
 	// Test with show-all
 	config.ShowAll = true
-	processAlerts(alerts, config)
+	if err := processAlerts(alerts, config); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
 	
 	// Test with groupby
 	config.ShowAll = false
 	config.GroupBy = "severity"
-	processAlerts(alerts, config)
+	if err := processAlerts(alerts, config); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
 }
 
 // Benchmark test for CLI parsing
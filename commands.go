@@ -0,0 +1,389 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// newCommandFlagSet creates the flag set of a subcommand with the usual help layout
+func newCommandFlagSet(name, usage, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf("USAGE:\n  %s %s %s\n\n", AppName, name, usage)
+		fmt.Println(description)
+		fmt.Println("\nFLAGS:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// loadPreparedAlerts validates the configuration of a subcommand, then loads
+// its input and runs it through the pipeline
+func loadPreparedAlerts(config *Config) (*Alerts, error) {
+	alerts, err := loadInputAlerts(config)
+	if err != nil {
+		return nil, err
+	}
+	prepared := config.pipeline().Run(*alerts)
+	return &prepared, nil
+}
+
+// loadPreparedFile loads another file of a subcommand, such as an earlier
+// export, and runs it through the same pipeline as its input. The input must
+// have been loaded first, so the configuration is validated and loaded.
+func loadPreparedFile(config *Config, filename string) (*Alerts, error) {
+	alerts, err := loadAlertsFromFile(filename, config.loadOptions())
+	if err != nil {
+		return nil, err
+	}
+	prepared := config.pipeline().Run(*alerts)
+	return &prepared, nil
+}
+
+// loadInputAlerts validates the configuration of a subcommand, then loads and
+// checks its input
+func loadInputAlerts(config *Config) (*Alerts, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if err := config.load(); err != nil {
+		return nil, err
+	}
+	if err := checkSchema(config); err != nil {
+		return nil, err
+	}
+
+	alerts, err := loadAlertsFromFile(config.InputFile, config.loadOptions())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStrict(*alerts, config); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+// writeOutput runs render with the output file, if one was given, or stdout
+func writeOutput(config *Config, render func(w io.Writer) error) error {
+	if config.OutputFile == "" {
+		return render(os.Stdout)
+	}
+
+	file, err := os.Create(config.OutputFile)
+	if err != nil {
+		return fmt.Errorf("error creating output file '%s': %v", config.OutputFile, err)
+	}
+	err = render(file)
+
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("error writing output file '%s': %v", config.OutputFile, closeErr)
+	}
+	return err
+}
+
+// printJSON prints a value to w as indented JSON
+func printJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %v", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+func runTopCommand(args []string) error {
+	config := newConfig()
+	fs := newCommandFlagSet("top", "-i <input-file> [-n N] [OPTIONS]",
+		"Shows the highest priority alerts and summary statistics.")
+	registerInputFlags(fs, config)
+	registerOutputFlags(fs, config)
+	registerTimeFlags(fs, config)
+	fs.IntVar(&config.Top, "n", config.Top, "Number of alerts to show, 0 for all")
+	fs.IntVar(&config.Top, "top", config.Top, "Number of alerts to show, 0 for all")
+	registerAnalysisFlags(fs, config)
+	registerValidationFlags(fs, config)
+	registerRankingFlags(fs, config)
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	input, err := loadInputAlerts(config)
+	if err != nil {
+		return err
+	}
+	prepared := config.pipeline().With(TopStage(config.Top, config.sortKeys, config.severities)).Run(*input)
+	alerts := &prepared
+
+	return writeOutput(config, func(w io.Writer) error {
+		if config.Format == "json" {
+			top := alerts.Alerts
+			if config.Top > 0 && len(top) > config.Top {
+				top = top[:config.Top]
+			}
+			return printJSON(w, Alerts{Alerts: top})
+		}
+		if len(alerts.Alerts) == 0 {
+			return nil
+		}
+		fmt.Fprintf(w, "📊 Loaded %d alerts from %s\n\n", len(alerts.Alerts), config.InputFile)
+		showTopAlerts(w, alerts, config)
+		showSummaryStats(w, alerts, config)
+		return nil
+	})
+}
+
+func runListCommand(args []string) error {
+	config := newConfig()
+	fs := newCommandFlagSet("list", "-i <input-file> [OPTIONS]",
+		"Shows all alerts in detailed format, highest priority first.")
+	registerInputFlags(fs, config)
+	registerOutputFlags(fs, config)
+	registerTimeFlags(fs, config)
+	registerAnalysisFlags(fs, config)
+	registerValidationFlags(fs, config)
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	alerts, err := loadPreparedAlerts(config)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(config, func(w io.Writer) error {
+		if config.Format == "json" {
+			return printJSON(w, alerts)
+		}
+		alerts.PrettyPrintWith(w, config.display)
+		return nil
+	})
+}
+
+func runGroupCommand(args []string) error {
+	config := newConfig()
+	fs := newCommandFlagSet("group", "-i <input-file> --by <field> [OPTIONS]",
+		"Groups alerts by a field.")
+	registerInputFlags(fs, config)
+	registerOutputFlags(fs, config)
+	registerTimeFlags(fs, config)
+	fs.StringVar(&config.GroupBy, "by", "", "Field to group by ("+groupableFieldsHelp()+")")
+	fs.StringVar(&config.GroupBy, "groupby", "", "Alias of --by")
+	registerAnalysisFlags(fs, config)
+	registerValidationFlags(fs, config)
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if config.GroupBy == "" {
+		return fmt.Errorf("group field is required. Use --by to specify it")
+	}
+
+	alerts, err := loadPreparedAlerts(config)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(config, func(w io.Writer) error {
+		if config.Format == "json" {
+			return printJSON(w, alerts.Group(normalizeField(config.GroupBy)))
+		}
+		fmt.Fprintf(w, "📋 Grouping alerts by: %s\n", config.GroupBy)
+		alerts.PrettyPrintGroupedBy(w, config.GroupBy)
+		return nil
+	})
+}
+
+// StatsReport holds the summary, rankings and trends of a set of alerts
+type StatsReport struct {
+	Summary  SummaryStats `json:"summary"`
+	Rankings []Ranking    `json:"rankings"`
+	Trends   TrendStats   `json:"trends"`
+}
+
+func buildStatsReport(alerts Alerts, config *Config) StatsReport {
+	report := StatsReport{
+		Summary: summarizeAlerts(alerts),
+		Trends:  buildTrends(alerts, config.TrendsWindow, config.display.Location),
+	}
+	for _, dimension := range rankingDimensions {
+		for _, metric := range config.rankMetrics {
+			report.Rankings = append(report.Rankings, alerts.Rank(dimension, metric, config.RankTop))
+		}
+	}
+	return report
+}
+
+func runStatsCommand(args []string) error {
+	config := newConfig()
+	fs := newCommandFlagSet("stats", "-i <input-file> [OPTIONS]",
+		"Shows summary statistics, rankings and hourly trends.")
+	registerInputFlags(fs, config)
+	registerOutputFlags(fs, config)
+	registerTimeFlags(fs, config)
+	registerAnalysisFlags(fs, config)
+	registerValidationFlags(fs, config)
+	registerRankingFlags(fs, config)
+	fs.DurationVar(&config.TrendsWindow, "trends-window", config.TrendsWindow, "Window for trends, ending with the latest alert")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	alerts, err := loadPreparedAlerts(config)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(config, func(w io.Writer) error {
+		if config.Format == "json" {
+			return printJSON(w, buildStatsReport(*alerts, config))
+		}
+		if len(alerts.Alerts) == 0 {
+			return nil
+		}
+		showSummaryStats(w, alerts, config)
+		fmt.Fprintln(w)
+		return showTrends(w, buildTrends(*alerts, config.TrendsWindow, config.display.Location), "text")
+	})
+}
+
+func runDiffCommand(args []string) error {
+	config := newConfig()
+	var previousFile string
+	fs := newCommandFlagSet("diff", "-i <input-file> --previous <input-file> [OPTIONS]",
+		"Compares two exports: alerts that are new, resolved or still firing.")
+	registerInputFlags(fs, config)
+	registerOutputFlags(fs, config)
+	registerAnalysisFlags(fs, config)
+	fs.StringVar(&previousFile, "previous", "", "Earlier export to compare against")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if previousFile == "" {
+		return fmt.Errorf("previous export is required. Use --previous to specify it")
+	}
+
+	// Both exports go through the same pipeline, so an alert filtered out of
+	// one is filtered out of the other too
+	current, err := loadPreparedAlerts(config)
+	if err != nil {
+		return err
+	}
+	previous, err := loadPreparedFile(config, previousFile)
+	if err != nil {
+		return err
+	}
+	diff := compareAlerts(*current, *previous)
+
+	return writeOutput(config, func(w io.Writer) error {
+		if config.Format == "json" {
+			return printJSON(w, diff)
+		}
+		prettyPrintDiff(w, diff)
+		return nil
+	})
+}
+
+func prettyPrintDiff(w io.Writer, diff AlertDiff) {
+	sections := []struct {
+		title  string
+		alerts []Alert
+	}{
+		{"🆕 New", diff.New},
+		{"✅ Resolved", diff.Resolved},
+		{"🔁 Ongoing", diff.Ongoing},
+	}
+
+	for _, section := range sections {
+		fmt.Fprintf(w, "\n%s: %d alerts\n", section.title, len(section.alerts))
+		fmt.Fprintln(w, strings.Repeat("-", 40))
+		for _, alert := range section.alerts {
+			fmt.Fprintf(w, "  %s | %.2f | %s | %s\n", alert.ID, alert.Priority, alert.Severity, alert.Identity())
+		}
+	}
+}
+
+func runValidateCommand(args []string) error {
+	config := newConfig()
+	fs := newCommandFlagSet("validate", "-i <input-file> [--rules <levels>] [OPTIONS]",
+		"Checks every alert of a file and exits non-zero if any rule at error level fails.\n\nRULES:\n"+validationRulesHelp())
+	registerInputFlags(fs, config)
+	registerOutputFlags(fs, config)
+	fs.StringVar(&config.Rules, "rules", config.Rules, "Validation levels per rule, e.g. zero-threshold=error,duplicate-id=off")
+	fs.BoolVar(&config.Schema, "schema", config.Schema, "Also check the input against the JSON Schema, with JSON pointers to mismatches")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := config.validate(); err != nil {
+		return err
+	}
+	if err := config.load(); err != nil {
+		return err
+	}
+
+	var schemaErrors []SchemaError
+	if config.Schema {
+		var err error
+		if schemaErrors, err = validateFileSchema(config.InputFile, config.loadOptions()); err != nil {
+			return err
+		}
+	}
+
+	// A file that doesn't match the schema may not load at all, so its schema
+	// errors are all that can be reported
+	alerts, err := loadAlertsFromFile(config.InputFile, config.loadOptions())
+	if err != nil && len(schemaErrors) == 0 {
+		return err
+	}
+	var problems []ValidationProblem
+	if err == nil {
+		problems = alerts.Validate(config.ruleLevels, config.severities)
+	}
+	report := newValidationReport(config.InputFile, alerts, schemaErrors, problems)
+
+	err = writeOutput(config, func(w io.Writer) error {
+		if config.Format == "json" {
+			return printJSON(w, report)
+		}
+		showValidationReport(w, report)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if report.Errors > 0 {
+		return fmt.Errorf("'%s' failed validation with %d errors", config.InputFile, report.Errors)
+	}
+	return nil
+}
+
+// validationRulesHelp lists the validation rules for usage messages
+func validationRulesHelp() string {
+	var help strings.Builder
+	for _, rule := range validationRules {
+		fmt.Fprintf(&help, "  %-18s %-8s %s\n", rule.Name, rule.Level, rule.Description)
+	}
+	return strings.TrimRight(help.String(), "\n")
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTopCommand_JSON(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	outputFile := filepath.Join(t.TempDir(), "top.json")
+
+	if err := runTopCommand([]string{"-i", testFile, "-n", "1", "--format=json", "-o", outputFile}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Expected output file, got: %v", err)
+	}
+	// Priority is calculated, so it is only written, not read back into Alert
+	var top struct {
+		Alerts []struct {
+			ID       string
+			Priority float64
+		} `json:"alerts"`
+	}
+	if err := json.Unmarshal(data, &top); err != nil {
+		t.Fatalf("Expected JSON alerts, got: %v", err)
+	}
+	if len(top.Alerts) != 1 || top.Alerts[0].ID != "ALT-001" || top.Alerts[0].Priority == 0 {
+		t.Errorf("Expected the scored critical alert on top, got %+v", top.Alerts)
+	}
+}
+
+func TestRunGroupCommand_JSON(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	outputFile := filepath.Join(t.TempDir(), "groups.json")
+
+	if err := runGroupCommand([]string{"-i", testFile, "--by=Severity", "--format=json", "-o", outputFile}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, _ := os.ReadFile(outputFile)
+	var groups map[string]Alerts
+	if err := json.Unmarshal(data, &groups); err != nil {
+		t.Fatalf("Expected JSON groups, got: %v", err)
+	}
+	if len(groups["critical"].Alerts) != 1 || len(groups["warning"].Alerts) != 1 {
+		t.Errorf("Unexpected groups: %v", groups)
+	}
+
+	if err := runGroupCommand([]string{"-i", testFile}); err == nil || !strings.Contains(err.Error(), "--by") {
+		t.Errorf("Expected error about missing group field, got: %v", err)
+	}
+}
+
+func TestRunDiffCommand_JSON(t *testing.T) {
+	current := createTestFile(t, testJSONContent)
+	defer os.Remove(current)
+	previous := createTestFile(t, `{"alerts": [
+  {"id": "OLD-1", "service": "test-service", "component": "test-component", "metric": "latency", "severity": "critical"},
+  {"id": "OLD-2", "service": "db", "component": "primary", "metric": "lag", "severity": "warning"}
+]}`)
+	defer os.Remove(previous)
+	outputFile := filepath.Join(t.TempDir(), "diff.json")
+
+	if err := runDiffCommand([]string{"-i", current, "--previous", previous, "--format=json", "-o", outputFile}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, _ := os.ReadFile(outputFile)
+	var diff AlertDiff
+	if err := json.Unmarshal(data, &diff); err != nil {
+		t.Fatalf("Expected JSON diff, got: %v", err)
+	}
+	if len(diff.New) != 1 || len(diff.Resolved) != 1 || len(diff.Ongoing) != 1 {
+		t.Errorf("Expected 1 new, 1 resolved and 1 ongoing alert, got %+v", diff)
+	}
+}
+
+func TestRunDiffCommand_Filters(t *testing.T) {
+	current := createTestFile(t, testJSONContent)
+	defer os.Remove(current)
+	previous := createTestFile(t, `{"alerts": [
+  {"id": "OLD-1", "service": "test-service", "component": "test-component", "metric": "latency", "severity": "critical"},
+  {"id": "OLD-2", "service": "db", "component": "primary", "metric": "lag", "severity": "warning"}
+]}`)
+	defer os.Remove(previous)
+	outputFile := filepath.Join(t.TempDir(), "diff.json")
+
+	// The warnings are filtered out of both exports, so neither is new or resolved
+	args := []string{"-i", current, "--previous", previous, "--min-severity=critical", "--format=json", "-o", outputFile}
+	if err := runDiffCommand(args); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, _ := os.ReadFile(outputFile)
+	var diff AlertDiff
+	if err := json.Unmarshal(data, &diff); err != nil {
+		t.Fatalf("Expected JSON diff, got: %v", err)
+	}
+	if len(diff.New) != 0 || len(diff.Resolved) != 0 || len(diff.Ongoing) != 1 {
+		t.Errorf("Expected only 1 ongoing alert, got %+v", diff)
+	}
+}
+
+func TestRunStatsCommand_JSON(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	outputFile := filepath.Join(t.TempDir(), "stats.json")
+
+	if err := runStatsCommand([]string{"-i", testFile, "--rank-by=critical", "--format=json", "-o", outputFile}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, _ := os.ReadFile(outputFile)
+	var report StatsReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Expected JSON stats, got: %v", err)
+	}
+	if report.Summary.Total != 2 || len(report.Rankings) != 3 || report.Trends.Total != 2 {
+		t.Errorf("Unexpected stats report: %+v", report)
+	}
+}
+
+func TestCommands_InvalidFlags(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+
+	tests := map[string]error{
+		"top with invalid format": runTopCommand([]string{"-i", testFile, "--format=xml"}),
+		"list without input":      runListCommand([]string{}),
+		"diff without previous":   runDiffCommand([]string{"-i", testFile}),
+		"validate missing file":   runValidateCommand([]string{"-i", "missing.json"}),
+	}
+	for name, err := range tests {
+		if err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// DetectorConfig holds the thresholds for flapping and storm detection
+type DetectorConfig struct {
+	FlapWindow    time.Duration // Sliding window for counting state changes
+	FlapThreshold int           // State changes within FlapWindow that count as flapping
+	StormWindow   time.Duration // Bucket size for alert rates
+	StormFactor   float64       // How far above the service's baseline rate a bucket must be
+	StormMinimum  int           // Minimum alerts in a bucket to call it a storm
+}
+
+// DefaultDetectorConfig returns the default detector thresholds
+func DefaultDetectorConfig() DetectorConfig {
+	return DetectorConfig{
+		FlapWindow:    time.Hour,
+		FlapThreshold: 4,
+		StormWindow:   10 * time.Minute,
+		StormFactor:   3,
+		StormMinimum:  5,
+	}
+}
+
+// withDefaults fills unset thresholds with their defaults
+func (config DetectorConfig) withDefaults() DetectorConfig {
+	defaults := DefaultDetectorConfig()
+	if config.FlapWindow <= 0 {
+		config.FlapWindow = defaults.FlapWindow
+	}
+	if config.FlapThreshold <= 0 {
+		config.FlapThreshold = defaults.FlapThreshold
+	}
+	if config.StormWindow <= 0 {
+		config.StormWindow = defaults.StormWindow
+	}
+	if config.StormFactor <= 0 {
+		config.StormFactor = defaults.StormFactor
+	}
+	if config.StormMinimum <= 0 {
+		config.StormMinimum = defaults.StormMinimum
+	}
+	return config
+}
+
+// Storm is a burst of alerts for one service
+type Storm struct {
+	Service  string
+	Start    time.Time
+	Count    int
+	Baseline float64 // Average alerts per window for the service
+}
+
+// DetectionResult reports what the detectors found
+type DetectionResult struct {
+	Flapping  map[string]int // Identity -> number of firings, for flapping identities
+	Storms    []Storm
+	Collapsed int // Alerts folded into their flapping identity's latest firing
+}
+
+// DetectFlappingAndStorms flags alerts that are part of a storm and
+// collapses every flapping identity into its latest firing, which carries
+// the firing count. Each firing after the first implies the alert cleared in
+// between, so n firings within the window are 2(n-1) state changes.
+func (alerts Alerts) DetectFlappingAndStorms(config DetectorConfig) (Alerts, DetectionResult) {
+	config = config.withDefaults()
+	result := DetectionResult{Flapping: make(map[string]int)}
+	if len(alerts.Alerts) == 0 {
+		return alerts, result
+	}
+
+	// Flapping: state changes per identity within a sliding window
+	byIdentity := make(map[string][]time.Time)
+	for _, alert := range alerts.Alerts {
+		byIdentity[alert.Identity()] = append(byIdentity[alert.Identity()], alert.Timestamp)
+	}
+	for identity, times := range byIdentity {
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+		start := 0
+		for end := range times {
+			for times[end].Sub(times[start]) > config.FlapWindow {
+				start++
+			}
+			if 2*(end-start) >= config.FlapThreshold {
+				result.Flapping[identity] = len(times)
+				break
+			}
+		}
+	}
+
+	// Storms: per-service buckets well above the service's average rate
+	first, last := alerts.Alerts[0].Timestamp, alerts.Alerts[0].Timestamp
+	buckets := make(map[string]map[time.Time]int)
+	serviceTotals := make(map[string]int)
+	for _, alert := range alerts.Alerts {
+		if alert.Timestamp.Before(first) {
+			first = alert.Timestamp
+		}
+		if alert.Timestamp.After(last) {
+			last = alert.Timestamp
+		}
+		bucket := alert.Timestamp.Truncate(config.StormWindow)
+		if buckets[alert.Service] == nil {
+			buckets[alert.Service] = make(map[time.Time]int)
+		}
+		buckets[alert.Service][bucket]++
+		serviceTotals[alert.Service]++
+	}
+	windows := float64(last.Truncate(config.StormWindow).Sub(first.Truncate(config.StormWindow))/config.StormWindow) + 1
+
+	stormBuckets := make(map[string]map[time.Time]bool)
+	for service, counts := range buckets {
+		baseline := float64(serviceTotals[service]) / windows
+		for bucket, count := range counts {
+			if count >= config.StormMinimum && float64(count) > baseline*config.StormFactor {
+				result.Storms = append(result.Storms, Storm{Service: service, Start: bucket, Count: count, Baseline: baseline})
+				if stormBuckets[service] == nil {
+					stormBuckets[service] = make(map[time.Time]bool)
+				}
+				stormBuckets[service][bucket] = true
+			}
+		}
+	}
+	sort.Slice(result.Storms, func(i, j int) bool {
+		if !result.Storms[i].Start.Equal(result.Storms[j].Start) {
+			return result.Storms[i].Start.Before(result.Storms[j].Start)
+		}
+		return result.Storms[i].Service < result.Storms[j].Service
+	})
+
+	// Flag storms and keep only the latest firing of flapping identities
+	latest := make(map[string]int)
+	var flagged []Alert
+	for _, alert := range alerts.Alerts {
+		alert.Storm = stormBuckets[alert.Service][alert.Timestamp.Truncate(config.StormWindow)]
+
+		identity := alert.Identity()
+		count, flapping := result.Flapping[identity]
+		if !flapping {
+			flagged = append(flagged, alert)
+			continue
+		}
+
+		alert.Flapping = true
+		alert.FlapCount = count
+		if index, seen := latest[identity]; seen {
+			result.Collapsed++
+			if alert.Timestamp.After(flagged[index].Timestamp) {
+				flagged[index] = alert
+			}
+			continue
+		}
+		latest[identity] = len(flagged)
+		flagged = append(flagged, alert)
+	}
+
+	return Alerts{Alerts: flagged}, result
+}
+
+func showDetectionReport(w io.Writer, result DetectionResult) {
+	fmt.Fprintf(w, "🔁 Flapping: %d identities (%d repeat firings collapsed)\n", len(result.Flapping), result.Collapsed)
+	fmt.Fprintf(w, "⛈️  Storms: %d\n", len(result.Storms))
+	for _, storm := range result.Storms {
+		fmt.Fprintf(w, "  %s at %s: %d alerts (baseline %.2f per window)\n",
+			storm.Service, storm.Start.Format("2006-01-02 15:04 MST"), storm.Count, storm.Baseline)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDetectFlapping(t *testing.T) {
+	base := mustParseTime(t, "2024-04-28T10:00:00Z")
+	alerts := Alerts{Alerts: []Alert{
+		{ID: "ALT-1", Service: "db", Component: "primary", Metric: "lag", Timestamp: base},
+		{ID: "ALT-2", Service: "db", Component: "primary", Metric: "lag", Timestamp: base.Add(20 * time.Minute)},
+		{ID: "ALT-3", Service: "web", Component: "frontend", Metric: "errors", Timestamp: base.Add(25 * time.Minute)},
+		{ID: "ALT-4", Service: "db", Component: "primary", Metric: "lag", Timestamp: base.Add(40 * time.Minute)},
+		// Two firings far apart are not flapping
+		{ID: "ALT-5", Service: "web", Component: "frontend", Metric: "errors", Timestamp: base.Add(5 * time.Hour)},
+	}}
+
+	detected, result := alerts.DetectFlappingAndStorms(DefaultDetectorConfig())
+
+	if len(result.Flapping) != 1 || result.Flapping["db/primary/lag"] != 3 {
+		t.Errorf("Expected db/primary/lag to flap with 3 firings, got %v", result.Flapping)
+	}
+	if result.Collapsed != 2 {
+		t.Errorf("Expected 2 collapsed firings, got %d", result.Collapsed)
+	}
+	if len(detected.Alerts) != 3 {
+		t.Fatalf("Expected 3 alerts after collapsing, got %d", len(detected.Alerts))
+	}
+
+	// The latest firing represents the flapping identity
+	flapping := detected.FilterByDetection("flapping")
+	if len(flapping.Alerts) != 1 || flapping.Alerts[0].ID != "ALT-4" || flapping.Alerts[0].FlapCount != 3 {
+		t.Errorf("Expected ALT-4 to represent the flapping alert, got %+v", flapping.Alerts)
+	}
+}
+
+func TestDetectStorms(t *testing.T) {
+	base := mustParseTime(t, "2024-04-28T10:00:00Z")
+	var alerts Alerts
+
+	// A steady trickle of one alert per hour for a day...
+	for hour := 0; hour < 24; hour++ {
+		alerts.Alerts = append(alerts.Alerts, Alert{
+			ID: fmt.Sprintf("ALT-T%d", hour), Service: "checkout", Component: fmt.Sprintf("c%d", hour),
+			Metric: "errors", Timestamp: base.Add(time.Duration(hour) * time.Hour),
+		})
+	}
+	// ...and a burst of eight alerts within five minutes
+	for i := 0; i < 8; i++ {
+		alerts.Alerts = append(alerts.Alerts, Alert{
+			ID: fmt.Sprintf("ALT-S%d", i), Service: "checkout", Component: fmt.Sprintf("s%d", i),
+			Metric: "latency", Timestamp: base.Add(12*time.Hour + time.Duration(i)*30*time.Second),
+		})
+	}
+
+	detected, result := alerts.DetectFlappingAndStorms(DefaultDetectorConfig())
+
+	if len(result.Storms) != 1 {
+		t.Fatalf("Expected 1 storm, got %d", len(result.Storms))
+	}
+	storm := result.Storms[0]
+	if storm.Service != "checkout" || storm.Count != 9 || !storm.Start.Equal(base.Add(12*time.Hour)) {
+		t.Errorf("Unexpected storm: %+v", storm)
+	}
+
+	storms := detected.FilterByDetection("storm")
+	if len(storms.Alerts) != 9 {
+		t.Errorf("Expected 9 alerts flagged as storm, got %d", len(storms.Alerts))
+	}
+}
+
+func TestDetectFlappingAndStorms_Empty(t *testing.T) {
+	detected, result := Alerts{}.DetectFlappingAndStorms(DefaultDetectorConfig())
+	if len(detected.Alerts) != 0 || len(result.Flapping) != 0 || len(result.Storms) != 0 {
+		t.Error("Expected nothing detected for empty alerts")
+	}
+}
+
+func TestParseFlags_WithDetected(t *testing.T) {
+	resetFlags()
+
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--detected=flapping"}
+
+	config, err := parseFlags()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !config.Detect {
+		t.Error("Expected --detected to imply --detect")
+	}
+
+	resetFlags()
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--detected=noisy"}
+	if _, err := parseFlags(); err == nil {
+		t.Error("Expected error for invalid detected filter, got nil")
+	}
+}
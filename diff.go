@@ -0,0 +1,41 @@
+package main
+
+// AlertDiff compares two exports of alerts by identity
+type AlertDiff struct {
+	New      []Alert `json:"new"`      // Firing now but not in the previous export
+	Resolved []Alert `json:"resolved"` // In the previous export but no longer firing
+	Ongoing  []Alert `json:"ongoing"`  // Firing in both exports
+}
+
+// compareAlerts matches alerts by Identity. Results keep the order of the
+// export they come from.
+func compareAlerts(current, previous Alerts) AlertDiff {
+	currentIDs := make(map[string]bool, len(current.Alerts))
+	for _, alert := range current.Alerts {
+		currentIDs[alert.Identity()] = true
+	}
+	previousIDs := make(map[string]bool, len(previous.Alerts))
+	for _, alert := range previous.Alerts {
+		previousIDs[alert.Identity()] = true
+	}
+
+	var diff AlertDiff
+	for _, alert := range current.Alerts {
+		if previousIDs[alert.Identity()] {
+			diff.Ongoing = append(diff.Ongoing, alert)
+		} else {
+			diff.New = append(diff.New, alert)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, alert := range previous.Alerts {
+		identity := alert.Identity()
+		if !currentIDs[identity] && !seen[identity] {
+			seen[identity] = true
+			diff.Resolved = append(diff.Resolved, alert)
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const digestTextTemplate = `{{.Title}}
+Generated {{.Generated.Format "2006-01-02 15:04 MST"}} from {{.Summary.Total}} alerts
+
+TOP PRIORITY ALERTS
+{{range $i, $a := .Top}}{{inc $i}}. [{{printf "%.2f" $a.Priority}}] {{$a.Severity}} {{$a.ID}} {{$a.Service}}/{{$a.Component}}
+   {{$a.Description}}
+{{end}}
+BY SEVERITY
+{{range .Severities}}  {{.Name}}: {{.Count}}
+{{end}}
+BY SERVICE
+{{range .Services}}  {{.Name}}: {{.Count}}
+{{end}}
+Average priority: {{printf "%.2f" .Summary.AveragePriority}}
+{{if .HasPrevious}}
+TRENDS SINCE PREVIOUS EXPORT
+  New: {{len .Diff.New}}
+  Resolved: {{len .Diff.Resolved}}
+  Ongoing: {{len .Diff.Ongoing}}
+{{end}}`
+
+const digestHTMLTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif">
+<h2>{{.Title}}</h2>
+<p>Generated {{.Generated.Format "2006-01-02 15:04 MST"}} from {{.Summary.Total}} alerts</p>
+<h3>Top priority alerts</h3>
+<table cellpadding="4" border="1" style="border-collapse: collapse">
+<tr><th>#</th><th>Priority</th><th>Severity</th><th>ID</th><th>Service</th><th>Component</th><th>Description</th></tr>
+{{range $i, $a := .Top}}<tr><td>{{inc $i}}</td><td>{{printf "%.2f" $a.Priority}}</td><td>{{$a.Severity}}</td><td>{{$a.ID}}</td><td>{{$a.Service}}</td><td>{{$a.Component}}</td><td>{{$a.Description}}</td></tr>
+{{end}}</table>
+<h3>By severity</h3>
+<ul>{{range .Severities}}<li>{{.Name}}: {{.Count}}</li>{{end}}</ul>
+<h3>By service</h3>
+<ul>{{range .Services}}<li>{{.Name}}: {{.Count}}</li>{{end}}</ul>
+<p>Average priority: {{printf "%.2f" .Summary.AveragePriority}}</p>
+{{if .HasPrevious}}<h3>Trends since previous export</h3>
+<ul><li>New: {{len .Diff.New}}</li><li>Resolved: {{len .Diff.Resolved}}</li><li>Ongoing: {{len .Diff.Ongoing}}</li></ul>
+{{end}}</body>
+</html>
+`
+
+// Digest is the data rendered into a digest email
+type Digest struct {
+	Title       string
+	Generated   time.Time
+	Summary     SummaryStats
+	Severities  []countEntry
+	Services    []countEntry
+	Top         []Alert
+	HasPrevious bool
+	Diff        AlertDiff
+}
+
+// DigestEmail holds the envelope of a digest email
+type DigestEmail struct {
+	From    string
+	To      []string
+	Subject string
+}
+
+// MailConfig holds how digest sends its email
+type MailConfig struct {
+	From     string // Sender address
+	To       string // Comma-separated recipient addresses
+	Subject  string
+	SMTP     string // SMTP server address (host:port)
+	SMTPUser string // SMTP username; the password comes from the environment
+}
+
+// DefaultMailConfig returns the default mail settings
+func DefaultMailConfig() MailConfig {
+	return MailConfig{From: "enc-alertbuddy@localhost", Subject: "enc-alertbuddy daily digest"}
+}
+
+var digestFuncs = map[string]any{
+	"inc": func(i int) int { return i + 1 },
+}
+
+// buildDigest summarizes scored alerts, optionally comparing them with a
+// previous export. Severities are listed along the ladder.
+func buildDigest(alerts Alerts, previous *Alerts, severities *SeverityModel, top int, now time.Time) Digest {
+	digest := Digest{
+		Title:     "enc-alertbuddy daily digest",
+		Generated: now,
+		Summary:   summarizeAlerts(alerts),
+	}
+	digest.Severities = severityBreakdown(severities, digest.Summary.SeverityCounts)
+	digest.Services = sortedCounts(digest.Summary.ServiceCounts)
+	if len(digest.Services) > 10 {
+		digest.Services = digest.Services[:10]
+	}
+
+	sorted := Alerts{Alerts: append([]Alert(nil), alerts.Alerts...)}
+	sorted.SortByPriority()
+	if len(sorted.Alerts) > top {
+		sorted.Alerts = sorted.Alerts[:top]
+	}
+	digest.Top = sorted.Alerts
+
+	if previous != nil {
+		digest.HasPrevious = true
+		digest.Diff = compareAlerts(alerts, *previous)
+	}
+
+	return digest
+}
+
+// renderDigest renders the plaintext and HTML bodies of a digest
+func renderDigest(digest Digest) (string, string, error) {
+	textTmpl := template.Must(template.New("text").Funcs(digestFuncs).Parse(digestTextTemplate))
+	htmlTmpl := htmltemplate.Must(htmltemplate.New("html").Funcs(digestFuncs).Parse(digestHTMLTemplate))
+
+	var text, html strings.Builder
+	if err := textTmpl.Execute(&text, digest); err != nil {
+		return "", "", fmt.Errorf("error rendering plaintext digest: %v", err)
+	}
+	if err := htmlTmpl.Execute(&html, digest); err != nil {
+		return "", "", fmt.Errorf("error rendering HTML digest: %v", err)
+	}
+	return text.String(), html.String(), nil
+}
+
+// buildDigestMessage builds a multipart/alternative MIME message with a
+// plaintext and an HTML part
+func buildDigestMessage(email DigestEmail, text, html string, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	}
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", email.From)
+	if len(email.To) > 0 {
+		fmt.Fprintf(&message, "To: %s\r\n", strings.Join(email.To, ", "))
+	}
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n", writer.Boundary())
+	fmt.Fprintf(&message, "\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+func runDigestCommand(args []string) error {
+	config := newConfig()
+	var previousFile, outputFile string
+	var top int
+	fs := newCommandFlagSet("digest", "-i <input-file> (--smtp <host:port> --to <addresses> | -o <file.eml>) [OPTIONS]",
+		"Renders a digest email with the top alerts, counts by severity and service, and trends.")
+	registerInputFlags(fs, config)
+	registerAnalysisFlags(fs, config)
+	registerMailFlags(fs, config)
+	fs.StringVar(&previousFile, "previous", "", "Previous export to compute new and resolved alerts against")
+	fs.IntVar(&top, "top", 10, "Number of top priority alerts to include")
+	fs.StringVar(&outputFile, "o", "", "Write the digest to this .eml file instead of sending it")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mail := config.mail
+	if mail.SMTP == "" && outputFile == "" {
+		return fmt.Errorf("either --smtp or -o is required")
+	}
+	if mail.SMTP != "" && mail.To == "" {
+		return fmt.Errorf("recipients are required when sending. Use --to to specify them")
+	}
+	if top <= 0 {
+		return fmt.Errorf("top must be a positive number")
+	}
+
+	alerts, err := loadPreparedAlerts(config)
+	if err != nil {
+		return err
+	}
+	var previous *Alerts
+	if previousFile != "" {
+		if previous, err = loadPreparedFile(config, previousFile); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	digest := buildDigest(*alerts, previous, config.severities, top, now)
+	digest.Title = mail.Subject
+	text, html, err := renderDigest(digest)
+	if err != nil {
+		return err
+	}
+
+	recipients := splitList(mail.To)
+	email := DigestEmail{From: mail.From, To: recipients, Subject: mail.Subject}
+	message, err := buildDigestMessage(email, text, html, now)
+	if err != nil {
+		return fmt.Errorf("error building digest email: %v", err)
+	}
+
+	if outputFile != "" {
+		if err := os.WriteFile(outputFile, message, 0o644); err != nil {
+			return fmt.Errorf("error writing digest to '%s': %v", outputFile, err)
+		}
+		fmt.Printf("📝 Wrote digest of %d alerts to %s\n", len(alerts.Alerts), outputFile)
+	}
+
+	if mail.SMTP != "" {
+		var auth smtp.Auth
+		if mail.SMTPUser != "" {
+			host, _, err := net.SplitHostPort(mail.SMTP)
+			if err != nil {
+				return fmt.Errorf("invalid SMTP address '%s': %v", mail.SMTP, err)
+			}
+			auth = smtp.PlainAuth("", mail.SMTPUser, os.Getenv("ENC_ALERTBUDDY_SMTP_PASSWORD"), host)
+		}
+		if err := smtp.SendMail(mail.SMTP, auth, mail.From, recipients, message); err != nil {
+			return fmt.Errorf("error sending digest via '%s': %v", mail.SMTP, err)
+		}
+		fmt.Printf("📧 Sent digest of %d alerts to %s\n", len(alerts.Alerts), strings.Join(recipients, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildDigest(t *testing.T) {
+	alerts := createTestAlerts()
+	alerts.CalculateAllPriorities()
+
+	previous := Alerts{Alerts: []Alert{
+		alerts.Alerts[0],
+		{ID: "ALT-OLD", Service: "legacy", Component: "cron", Metric: "failures"},
+	}}
+
+	digest := buildDigest(alerts, &previous, defaultSeverities, 2, time.Now())
+	if len(digest.Top) != 2 {
+		t.Fatalf("Expected 2 top alerts, got %d", len(digest.Top))
+	}
+	if digest.Top[0].Priority < digest.Top[1].Priority {
+		t.Error("Expected top alerts to be sorted by priority")
+	}
+	if digest.Severities[0].Name != "critical" || digest.Severities[0].Count != 2 {
+		t.Errorf("Expected critical to lead the severity breakdown, got %+v", digest.Severities[0])
+	}
+	if len(digest.Diff.New) != 3 || len(digest.Diff.Resolved) != 1 || len(digest.Diff.Ongoing) != 1 {
+		t.Errorf("Expected 3 new, 1 resolved and 1 ongoing, got %d/%d/%d",
+			len(digest.Diff.New), len(digest.Diff.Resolved), len(digest.Diff.Ongoing))
+	}
+
+	// The caller's alerts must not be reordered
+	if alerts.Alerts[0].ID != "ALT-001" {
+		t.Error("Expected buildDigest not to sort the input alerts")
+	}
+
+	// Severities follow the ladder, not their counts
+	warnings := Alerts{Alerts: []Alert{{Severity: "warning"}, {Severity: "warning"}, {Severity: "critical"}}}
+	digest = buildDigest(warnings, nil, defaultSeverities, 2, time.Now())
+	if len(digest.Severities) != 2 || digest.Severities[0].Name != "critical" || digest.Severities[1].Count != 2 {
+		t.Errorf("Expected critical before warning, got %+v", digest.Severities)
+	}
+}
+
+func TestBuildDigestMessage(t *testing.T) {
+	alerts := createTestAlerts()
+	alerts.CalculateAllPriorities()
+	text, html, err := renderDigest(buildDigest(alerts, nil, defaultSeverities, 10, time.Now()))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	email := DigestEmail{From: "buddy@example.com", To: []string{"a@example.com", "b@example.com"}, Subject: "Daily digest ✅"}
+	message, err := buildDigestMessage(email, text, html, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("Expected a valid email, got: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != "Daily digest ✅" {
+		t.Errorf("Expected decoded subject, got %q", subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got %s (%v)", mediaType, err)
+	}
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var contentTypes []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		content, _ := io.ReadAll(quotedprintable.NewReader(part))
+		if !strings.Contains(string(content), "ALT-004") {
+			t.Errorf("Expected part %s to mention ALT-004", part.Header.Get("Content-Type"))
+		}
+	}
+	if len(contentTypes) != 2 || !strings.HasPrefix(contentTypes[0], "text/plain") || !strings.HasPrefix(contentTypes[1], "text/html") {
+		t.Errorf("Expected plaintext and HTML parts, got %v", contentTypes)
+	}
+}
+
+// startSMTPSink runs a minimal SMTP server that accepts one message
+func startSMTPSink(t *testing.T) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	received := make(chan string, 1)
+
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ready")
+
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					received <- data.String()
+					reply("250 OK")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "DATA"):
+				inData = true
+				reply("354 go ahead")
+			case strings.HasPrefix(command, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestRunDigestCommand(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	emlFile := filepath.Join(t.TempDir(), "digest.eml")
+	addr, received := startSMTPSink(t)
+
+	err := runDigestCommand([]string{"-i", testFile, "-o", emlFile, "--smtp", addr, "--to", "lead@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	eml, err := os.ReadFile(emlFile)
+	if err != nil {
+		t.Fatalf("Expected .eml file, got: %v", err)
+	}
+	if !bytes.Contains(eml, []byte("multipart/alternative")) {
+		t.Error("Expected .eml file to contain a multipart message")
+	}
+
+	select {
+	case message := <-received:
+		if !strings.Contains(message, "To: lead@example.com") {
+			t.Errorf("Expected message to be addressed to lead@example.com, got:\n%s", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP sink did not receive a message")
+	}
+
+	if err := runDigestCommand([]string{"-i", testFile}); err == nil {
+		t.Error("Expected error without --smtp or -o, got nil")
+	}
+}
+
+func TestRunDigestCommand_Settings(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	settingsFile := createTestFile(t, "recipients: [lead@example.com, sre@example.com]\nsubject: Weekly digest\n")
+	defer os.Remove(settingsFile)
+	emlFile := filepath.Join(t.TempDir(), "digest.eml")
+
+	if err := runDigestCommand([]string{"--config", settingsFile, "-i", testFile, "-o", emlFile}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	eml, err := os.ReadFile(emlFile)
+	if err != nil {
+		t.Fatalf("Expected .eml file, got: %v", err)
+	}
+	message, err := mail.ReadMessage(bytes.NewReader(eml))
+	if err != nil {
+		t.Fatalf("Expected a valid message, got: %v", err)
+	}
+	if message.Header.Get("Subject") != "Weekly digest" || message.Header.Get("To") != "lead@example.com, sre@example.com" {
+		t.Errorf("Expected the subject and recipients from the config file, got: %v", message.Header)
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EscalationPolicy is an on-call escalation policy. The first level is paged
+// when an alert reaches its min_priority; every further level is paged after
+// the previous level's wait unless the page was acknowledged by then.
+type EscalationPolicy struct {
+	Name     string            `json:"name"`
+	AckAfter string            `json:"ack_after,omitempty"` // Assumed time to acknowledge, empty means never
+	Levels   []EscalationLevel `json:"levels"`
+
+	ackAfter time.Duration
+}
+
+// EscalationLevel is a single level of an escalation policy
+type EscalationLevel struct {
+	Name        string   `json:"name"`
+	Targets     []string `json:"targets"`
+	Wait        string   `json:"wait,omitempty"`         // Time before escalating to the next level
+	MinPriority float64  `json:"min_priority,omitempty"` // Priority needed to page this level
+
+	wait time.Duration
+}
+
+// EscalationPage is a single simulated page
+type EscalationPage struct {
+	Time     time.Time
+	Level    int
+	Targets  []string
+	AlertID  string
+	Priority float64
+}
+
+// EscalationIncident groups the alerts of one identity that were paged as one
+type EscalationIncident struct {
+	Identity     string
+	AlertID      string
+	Opened       time.Time
+	Priority     float64
+	MaxLevel     int
+	Deduplicated int // Alerts folded into the incident while it was open
+
+	pages    []EscalationPage
+	closesAt time.Time
+}
+
+// EscalationReport is the result of an escalation simulation
+type EscalationReport struct {
+	Pages         []EscalationPage
+	Incidents     []*EscalationIncident
+	PagesPerLevel []int
+	PagesByTarget map[string]int
+	BelowMinimum  int // Alerts not paged because of their priority
+}
+
+func loadEscalationPolicy(filename string) (*EscalationPolicy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading escalation policy '%s': %v", filename, err)
+	}
+
+	var policy EscalationPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("error parsing escalation policy from '%s': %v", filename, err)
+	}
+	if err := policy.compile(); err != nil {
+		return nil, fmt.Errorf("invalid escalation policy in '%s': %v", filename, err)
+	}
+
+	return &policy, nil
+}
+
+// compile validates the policy and parses its durations
+func (p *EscalationPolicy) compile() error {
+	if len(p.Levels) == 0 {
+		return fmt.Errorf("policy needs at least one level")
+	}
+
+	if p.AckAfter != "" {
+		ackAfter, err := time.ParseDuration(p.AckAfter)
+		if err != nil || ackAfter <= 0 {
+			return fmt.Errorf("invalid ack_after '%s'", p.AckAfter)
+		}
+		p.ackAfter = ackAfter
+	}
+
+	for i := range p.Levels {
+		level := &p.Levels[i]
+		if level.Name == "" {
+			level.Name = fmt.Sprintf("level %d", i+1)
+		}
+		if len(level.Targets) == 0 {
+			return fmt.Errorf("level '%s' has no targets", level.Name)
+		}
+		if i == len(p.Levels)-1 {
+			continue // The last level has nowhere to escalate to
+		}
+		wait, err := time.ParseDuration(level.Wait)
+		if err != nil || wait <= 0 {
+			return fmt.Errorf("level '%s' needs a positive wait, got '%s'", level.Name, level.Wait)
+		}
+		level.wait = wait
+	}
+
+	return nil
+}
+
+// chain returns the pages for an incident opened at the given time and the
+// time the incident closes: when it is acknowledged, or when the last paged
+// level's wait runs out.
+func (p *EscalationPolicy) chain(opened time.Time, priority float64, alertID string) ([]EscalationPage, time.Time) {
+	var pages []EscalationPage
+	at := opened
+
+	for i, level := range p.Levels {
+		if priority < level.MinPriority {
+			break
+		}
+		if p.ackAfter > 0 && i > 0 && !at.Before(opened.Add(p.ackAfter)) {
+			return pages, opened.Add(p.ackAfter)
+		}
+
+		pages = append(pages, EscalationPage{Time: at, Level: i, Targets: level.Targets, AlertID: alertID, Priority: priority})
+		at = at.Add(level.wait)
+	}
+
+	if p.ackAfter > 0 && opened.Add(p.ackAfter).Before(at) {
+		return pages, opened.Add(p.ackAfter)
+	}
+	return pages, at
+}
+
+// SimulateEscalation replays alerts in timestamp order against the policy.
+// Alerts of the same identity arriving while an incident is open are folded
+// into it; a higher priority can escalate the incident further.
+func (alerts Alerts) SimulateEscalation(policy *EscalationPolicy) EscalationReport {
+	replay := append([]Alert(nil), alerts.Alerts...)
+	sort.SliceStable(replay, func(i, j int) bool {
+		return replay[i].Timestamp.Before(replay[j].Timestamp)
+	})
+
+	report := EscalationReport{
+		PagesPerLevel: make([]int, len(policy.Levels)),
+		PagesByTarget: make(map[string]int),
+	}
+	open := make(map[string]*EscalationIncident)
+
+	for _, alert := range replay {
+		if alert.Priority < policy.Levels[0].MinPriority {
+			report.BelowMinimum++
+			continue
+		}
+
+		identity := alert.Identity()
+		if incident, exists := open[identity]; exists && alert.Timestamp.Before(incident.closesAt) {
+			incident.Deduplicated++
+			if alert.Priority > incident.Priority {
+				incident.Priority = alert.Priority
+				incident.pages, incident.closesAt = policy.chain(incident.Opened, alert.Priority, incident.AlertID)
+			}
+			continue
+		}
+
+		incident := &EscalationIncident{Identity: identity, AlertID: alert.ID, Opened: alert.Timestamp, Priority: alert.Priority}
+		incident.pages, incident.closesAt = policy.chain(alert.Timestamp, alert.Priority, alert.ID)
+		open[identity] = incident
+		report.Incidents = append(report.Incidents, incident)
+	}
+
+	for _, incident := range report.Incidents {
+		for _, page := range incident.pages {
+			report.Pages = append(report.Pages, page)
+			report.PagesPerLevel[page.Level]++
+			for _, target := range page.Targets {
+				report.PagesByTarget[target]++
+			}
+			incident.MaxLevel = page.Level
+		}
+	}
+	sort.SliceStable(report.Pages, func(i, j int) bool {
+		return report.Pages[i].Time.Before(report.Pages[j].Time)
+	})
+
+	return report
+}
+
+func prettyPrintEscalation(report EscalationReport, policy *EscalationPolicy) {
+	fmt.Println("📟 Escalation Simulation")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("Incidents: %d | Pages: %d | Below minimum priority: %d\n",
+		len(report.Incidents), len(report.Pages), report.BelowMinimum)
+
+	fmt.Println("\n🪜 Pages per level:")
+	for i, level := range policy.Levels {
+		fmt.Printf("  L%d %s (%s): %d pages\n", i+1, level.Name, strings.Join(level.Targets, ", "), report.PagesPerLevel[i])
+	}
+
+	fmt.Println("\n👤 Pages per target:")
+	for _, entry := range sortedCounts(report.PagesByTarget) {
+		fmt.Printf("  %s: %d pages\n", entry.Name, entry.Count)
+	}
+
+	fmt.Println("\n🕒 Timeline:")
+	for _, page := range report.Pages {
+		fmt.Printf("  %s  L%d %s → %s  %s (%.2f)\n",
+			page.Time.Format("2006-01-02 15:04:05"), page.Level+1, policy.Levels[page.Level].Name,
+			strings.Join(page.Targets, ", "), page.AlertID, page.Priority)
+	}
+
+	var escalated []*EscalationIncident
+	for _, incident := range report.Incidents {
+		if incident.MaxLevel > 0 {
+			escalated = append(escalated, incident)
+		}
+	}
+	fmt.Printf("\n⬆️  Escalated alerts: %d\n", len(escalated))
+	for _, incident := range escalated {
+		fmt.Printf("  %s (%s) → L%d %s",
+			incident.AlertID, incident.Identity, incident.MaxLevel+1, policy.Levels[incident.MaxLevel].Name)
+		if incident.Deduplicated > 0 {
+			fmt.Printf(" (+%d repeats)", incident.Deduplicated)
+		}
+		fmt.Println()
+	}
+}
+
+func runSimulateEscalationCommand(args []string) error {
+	config := newConfig()
+	var policyFile string
+	var minPriority float64
+	fs := newCommandFlagSet("simulate-escalation", "-i <input-file> --policy <policy-file> [OPTIONS]",
+		"Replays alerts in timestamp order and reports who would have been paged when.")
+	registerInputFlags(fs, config)
+	registerAnalysisFlags(fs, config)
+	fs.StringVar(&policyFile, "policy", "", "JSON file with the escalation policy")
+	fs.Float64Var(&minPriority, "min-priority", -1, "Override the priority needed to page the first level")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if policyFile == "" {
+		return fmt.Errorf("escalation policy is required. Use --policy to specify it")
+	}
+
+	alerts, err := loadPreparedAlerts(config)
+	if err != nil {
+		return err
+	}
+	policy, err := loadEscalationPolicy(policyFile)
+	if err != nil {
+		return err
+	}
+	if minPriority >= 0 {
+		policy.Levels[0].MinPriority = minPriority
+	}
+
+	fmt.Printf("📊 Loaded %d alerts from %s\n\n", len(alerts.Alerts), config.InputFile)
+	prettyPrintEscalation(alerts.SimulateEscalation(policy), policy)
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createTestPolicy(t *testing.T, ackAfter string) *EscalationPolicy {
+	policy := &EscalationPolicy{
+		Name:     "payments",
+		AckAfter: ackAfter,
+		Levels: []EscalationLevel{
+			{Name: "primary", Targets: []string{"alice"}, Wait: "15m", MinPriority: 20},
+			{Name: "secondary", Targets: []string{"bob"}, Wait: "30m"},
+			{Name: "manager", Targets: []string{"carol"}, MinPriority: 50},
+		},
+	}
+	if err := policy.compile(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	return policy
+}
+
+func TestEscalationChain(t *testing.T) {
+	opened := mustParseTime(t, "2024-04-28T10:00:00Z")
+
+	// Never acknowledged: escalate as far as the priority allows
+	policy := createTestPolicy(t, "")
+	pages, closesAt := policy.chain(opened, 60, "ALT-1")
+	if len(pages) != 3 {
+		t.Fatalf("Expected 3 pages, got %d", len(pages))
+	}
+	if !pages[1].Time.Equal(opened.Add(15*time.Minute)) || !pages[2].Time.Equal(opened.Add(45*time.Minute)) {
+		t.Errorf("Unexpected page times: %v, %v", pages[1].Time, pages[2].Time)
+	}
+	if !closesAt.Equal(opened.Add(45 * time.Minute)) {
+		t.Errorf("Expected incident to close at the last page, got %v", closesAt)
+	}
+
+	// The manager level needs priority 50
+	pages, _ = policy.chain(opened, 30, "ALT-1")
+	if len(pages) != 2 {
+		t.Errorf("Expected 2 pages for priority 30, got %d", len(pages))
+	}
+
+	// Acknowledged after 20 minutes: only the secondary gets paged as well
+	policy = createTestPolicy(t, "20m")
+	pages, closesAt = policy.chain(opened, 60, "ALT-1")
+	if len(pages) != 2 {
+		t.Errorf("Expected 2 pages when acknowledged after 20m, got %d", len(pages))
+	}
+	if !closesAt.Equal(opened.Add(20 * time.Minute)) {
+		t.Errorf("Expected incident to close at acknowledgement, got %v", closesAt)
+	}
+}
+
+func TestSimulateEscalation(t *testing.T) {
+	base := mustParseTime(t, "2024-04-28T10:00:00Z")
+	alerts := Alerts{Alerts: []Alert{
+		// Out of order on purpose: the simulation replays by timestamp
+		{ID: "ALT-3", Service: "db", Component: "primary", Metric: "lag", Priority: 60, Timestamp: base.Add(10 * time.Minute)},
+		{ID: "ALT-1", Service: "db", Component: "primary", Metric: "lag", Priority: 30, Timestamp: base},
+		{ID: "ALT-2", Service: "web", Component: "frontend", Metric: "errors", Priority: 25, Timestamp: base.Add(5 * time.Minute)},
+		{ID: "ALT-4", Service: "web", Component: "frontend", Metric: "errors", Priority: 5, Timestamp: base.Add(6 * time.Minute)},
+	}}
+
+	report := alerts.SimulateEscalation(createTestPolicy(t, "20m"))
+
+	if report.BelowMinimum != 1 {
+		t.Errorf("Expected 1 alert below minimum priority, got %d", report.BelowMinimum)
+	}
+	if len(report.Incidents) != 2 {
+		t.Fatalf("Expected 2 incidents, got %d", len(report.Incidents))
+	}
+
+	db := report.Incidents[0]
+	if db.AlertID != "ALT-1" || db.Deduplicated != 1 || db.Priority != 60 {
+		t.Errorf("Expected ALT-3 to be folded into ALT-1 and raise its priority, got %+v", db)
+	}
+	if db.MaxLevel != 1 {
+		t.Errorf("Expected db incident to reach the secondary before acknowledgement, got level %d", db.MaxLevel+1)
+	}
+
+	if len(report.Pages) != 4 {
+		t.Errorf("Expected 4 pages, got %d", len(report.Pages))
+	}
+	for i := 1; i < len(report.Pages); i++ {
+		if report.Pages[i].Time.Before(report.Pages[i-1].Time) {
+			t.Error("Expected pages to be ordered by time")
+		}
+	}
+	if report.PagesByTarget["alice"] != 2 || report.PagesByTarget["bob"] != 2 {
+		t.Errorf("Unexpected pages per target: %v", report.PagesByTarget)
+	}
+}
+
+func TestLoadEscalationPolicy(t *testing.T) {
+	valid := `{"name": "default", "ack_after": "10m", "levels": [
+  {"name": "primary", "targets": ["alice"], "wait": "5m", "min_priority": 40},
+  {"name": "secondary", "targets": ["bob"]}
+]}`
+	testFile := createTestFile(t, valid)
+	defer os.Remove(testFile)
+
+	policy, err := loadEscalationPolicy(testFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if policy.ackAfter != 10*time.Minute || policy.Levels[0].wait != 5*time.Minute {
+		t.Errorf("Expected durations to be parsed, got %+v", policy)
+	}
+
+	invalid := map[string]string{
+		`{"levels": []}`: "at least one level",
+		`{"levels": [{"name": "a", "targets": ["x"]}, {"name": "b", "targets": ["y"]}]}`: "positive wait",
+		`{"levels": [{"name": "a"}]}`:                                        "no targets",
+		`{"ack_after": "soon", "levels": [{"name": "a", "targets": ["x"]}]}`: "invalid ack_after",
+	}
+	for content, expected := range invalid {
+		invalidFile := createTestFile(t, content)
+		defer os.Remove(invalidFile)
+
+		_, err := loadEscalationPolicy(invalidFile)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing '%s', got: %v", expected, err)
+		}
+	}
+}
+
+func TestRunSimulateEscalationCommand_Settings(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	policyFile := createTestFile(t, `{"levels": [{"name": "primary", "targets": ["alice"]}]}`)
+	defer os.Remove(policyFile)
+	settingsFile := createTestFile(t, "input: "+testFile+"\n")
+	defer os.Remove(settingsFile)
+
+	if err := runSimulateEscalationCommand([]string{"--config", settingsFile, "--policy", policyFile}); err != nil {
+		t.Errorf("Expected the input from the config file, got: %v", err)
+	}
+}
@@ -1,12 +1,19 @@
 package main
 
-import "time"
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
 
-func (a Alerts) FilterBySeverity(s string) Alerts {
+// FilterBySeverity keeps alerts of a severity, ignoring case and the aliases
+// of the given ladder
+func (a Alerts) FilterBySeverity(model *SeverityModel, s string) Alerts {
 	var filtered []Alert
 
 	for _, alert := range a.Alerts {
-		if alert.Severity == s {
+		if strings.EqualFold(model.Normalize(alert.Severity), model.Normalize(s)) {
 			filtered = append(filtered, alert)
 		}
 	}
@@ -46,3 +53,64 @@ func (a Alerts) FilterByService(s string) Alerts {
 		Alerts: filtered,
 	}
 }
+
+// FilterByDetection keeps alerts flagged by the given detector ("flapping" or "storm")
+func (a Alerts) FilterByDetection(kind string) Alerts {
+	var filtered []Alert
+
+	for _, alert := range a.Alerts {
+		if (kind == "flapping" && alert.Flapping) || (kind == "storm" && alert.Storm) {
+			filtered = append(filtered, alert)
+		}
+	}
+
+	return Alerts{
+		Alerts: filtered,
+	}
+}
+
+// FilterByFields keeps alerts whose fields match all the given patterns,
+// using the same "|"-separated globs as routing matchers
+func (a Alerts) FilterByFields(filters map[string]string) Alerts {
+	var filtered []Alert
+
+	for _, alert := range a.Alerts {
+		matches := true
+		for field, pattern := range filters {
+			value, ok := alertFieldValue(alert, field)
+			if !ok || !matchesPattern(pattern, value) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, alert)
+		}
+	}
+
+	return Alerts{
+		Alerts: filtered,
+	}
+}
+
+// parseFieldFilters parses filters such as "labels.region=eu-*,severity=critical"
+func parseFieldFilters(spec string) (map[string]string, error) {
+	filters := make(map[string]string)
+	for _, item := range splitList(spec) {
+		field, pattern, found := strings.Cut(item, "=")
+		field = normalizeField(strings.TrimSpace(field))
+		if !found || field == "" {
+			return nil, fmt.Errorf("invalid filter '%s'. Use field=pattern", item)
+		}
+		if !isGroupableField(field) {
+			return nil, fmt.Errorf("invalid filter field '%s'. Valid fields: %s", field, groupableFieldsHelp())
+		}
+		for _, alternative := range strings.Split(pattern, "|") {
+			if _, err := path.Match(alternative, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s' for field '%s'", pattern, field)
+			}
+		}
+		filters[field] = pattern
+	}
+	return filters, nil
+}
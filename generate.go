@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GeneratorOptions describe a synthetic alert dataset
+type GeneratorOptions struct {
+	Count         int            // Alerts in total, including storms
+	Services      int            // Distinct services; a few of them fire most alerts
+	Components    int            // Components per service
+	SeverityMix   map[string]int // Relative weight of each severity
+	Span          time.Duration  // Time the alerts are spread over
+	End           time.Time      // Time of the latest possible alert
+	Storms        int            // Alert storms to inject
+	StormSize     int            // Alerts per storm
+	StormDuration time.Duration  // How long a storm lasts
+	Seed          int64          // Same seed and options, same dataset
+}
+
+// DefaultGeneratorOptions returns the options of the generate command
+func DefaultGeneratorOptions() GeneratorOptions {
+	return GeneratorOptions{
+		Count:         1000,
+		Services:      20,
+		Components:    5,
+		SeverityMix:   map[string]int{"critical": 10, "warning": 30, "info": 60},
+		Span:          24 * time.Hour,
+		End:           time.Now().UTC().Truncate(time.Minute),
+		StormSize:     50,
+		StormDuration: 10 * time.Minute,
+		Seed:          1,
+	}
+}
+
+// generatorMetric is a metric alerts are generated for
+type generatorMetric struct {
+	Name        string
+	Threshold   float64
+	Below       bool // Alerts fire when the value drops below the threshold
+	Description string
+}
+
+var generatorMetrics = []generatorMetric{
+	{"cpu_usage", 80, false, "CPU usage above threshold"},
+	{"memory_usage", 85, false, "Memory usage above threshold"},
+	{"disk_usage", 90, false, "Disk almost full"},
+	{"latency", 500, false, "Response latency above threshold"},
+	{"error_rate", 5, false, "Error rate above threshold"},
+	{"queue_depth", 1000, false, "Queue is backing up"},
+	{"processing_lag", 300, false, "Processing lag behind schedule"},
+	{"cache_hit_ratio", 0.8, true, "Cache hit ratio below expected"},
+}
+
+var generatorServiceNames = []string{
+	"payment-service", "checkout-api", "search-service", "auth-service", "user-profile",
+	"inventory-service", "notification-service", "api-gateway", "order-processor", "analytics-platform",
+	"recommendation-engine", "billing-service", "shipping-tracker", "media-transcoder", "config-service",
+	"session-store", "fraud-detector", "report-generator", "message-queue", "audit-logger",
+}
+
+var generatorRoles = []string{"api", "worker", "db", "cache", "gateway", "scheduler"}
+
+var generatorRegions = []string{"eu-west-1", "eu-central-1", "us-east-1"}
+
+// validate checks the options and returns the severities of the mix in
+// name order
+func (options GeneratorOptions) validate() ([]string, error) {
+	if options.Count <= 0 || options.Services <= 0 || options.Components <= 0 {
+		return nil, fmt.Errorf("count, services and components must be positive")
+	}
+	if options.Span <= 0 {
+		return nil, fmt.Errorf("span must be positive")
+	}
+	if options.Storms < 0 || (options.Storms > 0 && (options.StormSize <= 0 || options.StormDuration <= 0)) {
+		return nil, fmt.Errorf("storms need a positive size and duration")
+	}
+	if options.Storms*options.StormSize > options.Count {
+		return nil, fmt.Errorf("%d storms of %d alerts don't fit in %d alerts", options.Storms, options.StormSize, options.Count)
+	}
+
+	var severities []string
+	total := 0
+	for severity, weight := range options.SeverityMix {
+		if weight < 0 {
+			return nil, fmt.Errorf("severity weight of '%s' must not be negative", severity)
+		}
+		total += weight
+		severities = append(severities, severity)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("the severity mix needs a positive weight")
+	}
+	sort.Strings(severities)
+	return severities, nil
+}
+
+// GenerateAlerts synthesizes a dataset: background alerts spread over the
+// span, with services picked on a skewed distribution, plus storms of
+// mostly critical alerts for one service each. Alerts are ordered by time.
+func GenerateAlerts(options GeneratorOptions) (Alerts, error) {
+	severities, err := options.validate()
+	if err != nil {
+		return Alerts{}, err
+	}
+	random := rand.New(rand.NewSource(options.Seed))
+	services := generatorServices(options.Services)
+	popularity := rand.NewZipf(random, 1.2, 2, uint64(options.Services-1))
+
+	total := 0
+	for _, severity := range severities {
+		total += options.SeverityMix[severity]
+	}
+	pickSeverity := func() string {
+		n := random.Intn(total)
+		for _, severity := range severities {
+			if n < options.SeverityMix[severity] {
+				return severity
+			}
+			n -= options.SeverityMix[severity]
+		}
+		return severities[len(severities)-1]
+	}
+
+	start := options.End.Add(-options.Span)
+	alerts := make([]Alert, 0, options.Count)
+	background := options.Count - options.Storms*options.StormSize
+	for i := 0; i < background; i++ {
+		service := int(popularity.Uint64())
+		at := start.Add(time.Duration(random.Int63n(int64(options.Span))))
+		alerts = append(alerts, generateAlert(random, services[service], options.Components, pickSeverity(), at))
+	}
+
+	for i := 0; i < options.Storms; i++ {
+		service := services[random.Intn(len(services))]
+		stormStart := start
+		if options.Span > options.StormDuration {
+			stormStart = start.Add(time.Duration(random.Int63n(int64(options.Span - options.StormDuration))))
+		}
+		for j := 0; j < options.StormSize; j++ {
+			severity := "critical"
+			if random.Intn(4) == 0 {
+				severity = pickSeverity()
+			}
+			at := stormStart.Add(time.Duration(random.Int63n(int64(options.StormDuration))))
+			alerts = append(alerts, generateAlert(random, service, options.Components, severity, at))
+		}
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].Timestamp.Before(alerts[j].Timestamp) })
+	width := len(strconv.Itoa(len(alerts)))
+	for i := range alerts {
+		alerts[i].ID = fmt.Sprintf("ALT-%0*d", width, i+1)
+	}
+	return Alerts{Alerts: alerts}, nil
+}
+
+// generatorServices names n services, numbering the built-in names once
+// they run out
+func generatorServices(n int) []string {
+	services := make([]string, n)
+	for i := range services {
+		services[i] = generatorServiceNames[i%len(generatorServiceNames)]
+		if round := i / len(generatorServiceNames); round > 0 {
+			services[i] += "-" + strconv.Itoa(round+1)
+		}
+	}
+	return services
+}
+
+func generateAlert(random *rand.Rand, service string, components int, severity string, at time.Time) Alert {
+	metric := generatorMetrics[random.Intn(len(generatorMetrics))]
+	component := random.Intn(components)
+
+	// Values overshoot the threshold by an exponentially distributed amount,
+	// more for higher severities
+	overshoot := random.ExpFloat64() * 0.15
+	if severity == "critical" {
+		overshoot *= 3
+	}
+	value := metric.Threshold * (1 + overshoot)
+	if metric.Below {
+		value = metric.Threshold / (1 + overshoot)
+	}
+
+	return Alert{
+		Timestamp:   at.Truncate(time.Second),
+		Service:     service,
+		Component:   fmt.Sprintf("%s-%s-%d", strings.Split(service, "-")[0], generatorRoles[component%len(generatorRoles)], component/len(generatorRoles)+1),
+		Severity:    severity,
+		Metric:      metric.Name,
+		Value:       math.Round(value*100) / 100,
+		Threshold:   metric.Threshold,
+		Description: metric.Description,
+		Labels:      map[string]string{"region": generatorRegions[component%len(generatorRegions)]},
+	}
+}
+
+// generatedAlert holds the input fields of an alert, leaving out the
+// calculated ones
+type generatedAlert struct {
+	ID          string            `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Service     string            `json:"service"`
+	Component   string            `json:"component"`
+	Severity    string            `json:"severity"`
+	Metric      string            `json:"metric"`
+	Value       float64           `json:"value"`
+	Threshold   float64           `json:"threshold"`
+	Description string            `json:"description"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// writeGeneratedAlerts writes alerts as an alerts file, one alert per line,
+// so large datasets are written without building the whole document
+func writeGeneratedAlerts(w io.Writer, alerts Alerts) error {
+	writer := bufio.NewWriter(w)
+	writer.WriteString("{\"alerts\": [\n")
+	for i, alert := range alerts.Alerts {
+		data, err := json.Marshal(generatedAlert{
+			ID: alert.ID, Timestamp: alert.Timestamp, Service: alert.Service, Component: alert.Component,
+			Severity: alert.Severity, Metric: alert.Metric, Value: alert.Value, Threshold: alert.Threshold,
+			Description: alert.Description, Labels: alert.Labels,
+		})
+		if err != nil {
+			return fmt.Errorf("error encoding alert '%s': %v", alert.ID, err)
+		}
+		writer.Write(data)
+		if i < len(alerts.Alerts)-1 {
+			writer.WriteString(",")
+		}
+		writer.WriteString("\n")
+	}
+	writer.WriteString("]}\n")
+	return writer.Flush()
+}
+
+// parseSeverityMix parses weights such as critical=10,warning=30,info=60
+func parseSeverityMix(spec string) (map[string]int, error) {
+	mix := make(map[string]int)
+	for _, item := range splitList(spec) {
+		severity, weight, found := strings.Cut(item, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(weight))
+		if !found || err != nil || strings.TrimSpace(severity) == "" {
+			return nil, fmt.Errorf("invalid severity mix '%s'. Use severity=weight pairs, e.g. critical=10,warning=30,info=60", item)
+		}
+		mix[strings.ToLower(strings.TrimSpace(severity))] = n
+	}
+	return mix, nil
+}
+
+func runGenerateCommand(args []string) error {
+	options := DefaultGeneratorOptions()
+	var outputFile, severityMix, end string
+	fs := newCommandFlagSet("generate", "[-n <count>] [-o <file>] [OPTIONS]",
+		"Generates a synthetic alerts file, for benchmarks and trying out scoring changes.")
+	fs.IntVar(&options.Count, "n", options.Count, "Number of alerts, including storms")
+	fs.IntVar(&options.Count, "count", options.Count, "Number of alerts, including storms")
+	fs.IntVar(&options.Services, "services", options.Services, "Number of distinct services")
+	fs.IntVar(&options.Components, "components", options.Components, "Number of components per service")
+	fs.StringVar(&severityMix, "severity-mix", "critical=10,warning=30,info=60", "Relative weight of each severity")
+	fs.DurationVar(&options.Span, "span", options.Span, "Time the alerts are spread over")
+	fs.StringVar(&end, "end", "", "Time of the latest possible alert, in RFC 3339 (default now)")
+	fs.IntVar(&options.Storms, "storms", options.Storms, "Number of alert storms to inject")
+	fs.IntVar(&options.StormSize, "storm-size", options.StormSize, "Alerts per storm")
+	fs.DurationVar(&options.StormDuration, "storm-duration", options.StormDuration, "How long a storm lasts")
+	fs.Int64Var(&options.Seed, "seed", options.Seed, "Random seed; the same seed, options and --end give the same alerts")
+	fs.StringVar(&outputFile, "o", "", "Output file (default stdout)")
+	fs.StringVar(&outputFile, "output", "", "Output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if options.SeverityMix, err = parseSeverityMix(severityMix); err != nil {
+		return err
+	}
+	if end != "" {
+		if options.End, err = parseTimestamp(end); err != nil {
+			return fmt.Errorf("invalid end time: %v", err)
+		}
+	}
+
+	alerts, err := GenerateAlerts(options)
+	if err != nil {
+		return err
+	}
+	return writeOutput(&Config{OutputFile: outputFile}, func(w io.Writer) error {
+		return writeGeneratedAlerts(w, alerts)
+	})
+}
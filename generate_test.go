@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testGeneratorOptions(count int) GeneratorOptions {
+	options := DefaultGeneratorOptions()
+	options.Count = count
+	options.End = time.Date(2025, 4, 22, 12, 0, 0, 0, time.UTC)
+	return options
+}
+
+func TestGenerateAlerts(t *testing.T) {
+	options := testGeneratorOptions(2000)
+	options.Storms = 2
+	options.SeverityMix = map[string]int{"critical": 1, "warning": 1, "info": 0}
+
+	alerts, err := GenerateAlerts(options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(alerts.Alerts) != 2000 {
+		t.Fatalf("Expected 2000 alerts, got %d", len(alerts.Alerts))
+	}
+
+	services := make(map[string]bool)
+	for i, alert := range alerts.Alerts {
+		services[alert.Service] = true
+		if alert.Severity == "info" {
+			t.Fatalf("Expected no info alerts with a zero weight, got %+v", alert)
+		}
+		if alert.Timestamp.After(options.End) || alert.Timestamp.Before(options.End.Add(-options.Span)) {
+			t.Fatalf("Expected %s within the span, got %v", alert.ID, alert.Timestamp)
+		}
+		if i > 0 && alert.Timestamp.Before(alerts.Alerts[i-1].Timestamp) {
+			t.Fatalf("Expected alerts in time order at %s", alert.ID)
+		}
+	}
+	if len(services) > options.Services {
+		t.Errorf("Expected at most %d services, got %d", options.Services, len(services))
+	}
+
+	again, _ := GenerateAlerts(options)
+	if !reflect.DeepEqual(alerts, again) {
+		t.Error("Expected the same seed to generate the same alerts")
+	}
+	options.Seed = 2
+	if other, _ := GenerateAlerts(options); reflect.DeepEqual(alerts, other) {
+		t.Error("Expected another seed to generate other alerts")
+	}
+
+	detected, result := alerts.DetectFlappingAndStorms(DefaultDetectorConfig())
+	if len(result.Storms) == 0 || len(detected.Alerts) == 0 {
+		t.Errorf("Expected the injected storms to be detected, got %+v", result.Storms)
+	}
+}
+
+func TestGeneratedAlertsRoundTrip(t *testing.T) {
+	alerts, err := GenerateAlerts(testGeneratorOptions(100))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := writeGeneratedAlerts(&buf, alerts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testFile := createTestFile(t, buf.String())
+	defer os.Remove(testFile)
+
+	errs, err := validateFileSchema(testFile, LoadOptions{})
+	if err != nil || len(errs) != 0 {
+		t.Errorf("Expected generated alerts to match the schema, got %v, %v", errs, err)
+	}
+	loaded, err := loadAlertsFromFile(testFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(loaded.Alerts) != 100 || loaded.Alerts[99].ID != alerts.Alerts[99].ID || loaded.Alerts[0].Labels["region"] == "" {
+		t.Errorf("Expected the generated alerts back, got %d alerts", len(loaded.Alerts))
+	}
+}
+
+func TestGeneratorOptionErrors(t *testing.T) {
+	invalid := []func(*GeneratorOptions){
+		func(o *GeneratorOptions) { o.Count = 0 },
+		func(o *GeneratorOptions) { o.Span = 0 },
+		func(o *GeneratorOptions) { o.Storms = 30 }, // 30 storms of 50 alerts
+		func(o *GeneratorOptions) { o.SeverityMix = map[string]int{"critical": 0} },
+	}
+	for i, change := range invalid {
+		options := testGeneratorOptions(1000)
+		change(&options)
+		if _, err := GenerateAlerts(options); err == nil {
+			t.Errorf("Expected an error for invalid options %d", i)
+		}
+	}
+
+	if _, err := parseSeverityMix("critical=1,warning"); err == nil {
+		t.Error("Expected an error for a severity without a weight")
+	}
+}
+
+// benchmarkSizes are the dataset sizes of the benchmark suite
+var benchmarkSizes = []int{1000, 100000, 1000000}
+
+// benchmarkDatasets caches generated datasets, as generating 1M alerts takes
+// longer than most of what is measured
+var benchmarkDatasets = make(map[int]Alerts)
+
+func benchmarkDataset(b *testing.B, size int) Alerts {
+	if testing.Short() && size > 100000 {
+		b.Skip("Skipping 1M alerts in short mode")
+	}
+	if alerts, ok := benchmarkDatasets[size]; ok {
+		return alerts
+	}
+	options := testGeneratorOptions(size)
+	options.Services = 200
+	options.Components = 20
+	options.Storms = size / 10000
+	alerts, err := GenerateAlerts(options)
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+	benchmarkDatasets[size] = alerts
+	return alerts
+}
+
+func BenchmarkDatasetLoad(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			alerts := benchmarkDataset(b, size)
+			file, err := os.CreateTemp("", "bench_alerts_*.json")
+			if err != nil {
+				b.Fatalf("Failed to create temp file: %v", err)
+			}
+			defer os.Remove(file.Name())
+			if err := writeGeneratedAlerts(file, alerts); err != nil {
+				b.Fatalf("Failed to write alerts: %v", err)
+			}
+			file.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := loadAlertsFromFile(file.Name()); err != nil {
+					b.Fatalf("Unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDatasetPriorities(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			alerts := benchmarkDataset(b, size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				alerts.CalculateAllPriorities()
+			}
+		})
+	}
+}
+
+func BenchmarkDatasetGroup(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			alerts := benchmarkDataset(b, size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				alerts.Group("service")
+			}
+		})
+	}
+}
+
+func BenchmarkDatasetFilter(b *testing.B) {
+	filters, err := parseFieldFilters("labels.region=eu-*,severity=critical|warning")
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			alerts := benchmarkDataset(b, size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				alerts.FilterBySeverity(defaultSeverities, "critical")
+				alerts.FilterByMinSeverity(defaultSeverities, "warning")
+				alerts.FilterByFields(filters)
+			}
+		})
+	}
+}
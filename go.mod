@@ -4,4 +4,8 @@ go 1.23.0
 
 toolchain go1.23.10
 
-require golang.org/x/text v0.26.0
+require (
+	github.com/klauspost/compress v1.18.0
+	golang.org/x/text v0.26.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 
@@ -9,37 +10,76 @@ import (
 	"golang.org/x/text/language"
 )
 
-// Group groups alerts by any field using reflection
-func (alerts Alerts) Group(field string) map[string]Alerts {
-	grouped := make(map[string]Alerts)
+// groupableFields lists the alert fields that can be used for grouping and matching
+var groupableFields = []string{"severity", "service", "component", "metric", "threshold", "value", "priority"}
+
+// isGroupableField reports whether a field can be used for grouping and
+// matching: one of groupableFields, or a label or annotation
+func isGroupableField(field string) bool {
+	if _, _, ok := labelField(Alert{}, field); ok {
+		return true
+	}
+	return contains(groupableFields, field)
+}
+
+// groupableFieldsHelp lists the valid fields for error messages
+func groupableFieldsHelp() string {
+	return strings.Join(groupableFields, ", ") + ", labels.<name>, annotations.<name>"
+}
+
+// normalizeField lowercases a field name, except for the case-sensitive
+// name of a label or annotation
+func normalizeField(field string) string {
+	if _, name, ok := labelField(Alert{}, field); ok {
+		return strings.ToLower(field[:len(field)-len(name)]) + name
+	}
+	return strings.ToLower(field)
+}
+
+// alertFieldValue returns the string form of an alert field using reflection.
+// Labels and annotations are addressed as labels.<name> and annotations.<name>;
+// alerts without them have no value.
+func alertFieldValue(alert Alert, field string) (string, bool) {
+	if values, name, ok := labelField(alert, field); ok {
+		value, found := values[name]
+		return value, found
+	}
 
 	// Create title caser for proper field name capitalization
 	caser := cases.Title(language.English)
 
-	for _, alert := range alerts.Alerts {
-		var key string
+	// Use reflection to get the field value
+	alertValue := reflect.ValueOf(alert)
+	fieldValue := alertValue.FieldByName(caser.String(field))
+
+	if !fieldValue.IsValid() || !fieldValue.CanInterface() {
+		return "", false
+	}
+
+	// Convert field value to string for use as map key
+	switch fieldValue.Kind() {
+	case reflect.String:
+		return fieldValue.String(), true
+	case reflect.Float64, reflect.Float32:
+		return fmt.Sprintf("%.2f", fieldValue.Float()), true
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return fmt.Sprintf("%d", fieldValue.Int()), true
+	default:
+		return fmt.Sprintf("%v", fieldValue.Interface()), true
+	}
+}
 
-		// Use reflection to get the field value
-		alertValue := reflect.ValueOf(alert)
-		fieldValue := alertValue.FieldByName(caser.String(field))
+// Group groups alerts by any field using reflection
+func (alerts Alerts) Group(field string) map[string]Alerts {
+	grouped := make(map[string]Alerts)
 
-		if !fieldValue.IsValid() {
+	for _, alert := range alerts.Alerts {
+		key, ok := alertFieldValue(alert, field)
+		if !ok {
 			// If field doesn't exist, skip this alert
 			continue
 		}
 
-		// Convert field value to string for use as map key
-		switch fieldValue.Kind() {
-		case reflect.String:
-			key = fieldValue.String()
-		case reflect.Float64, reflect.Float32:
-			key = fmt.Sprintf("%.2f", fieldValue.Float())
-		case reflect.Int, reflect.Int64, reflect.Int32:
-			key = fmt.Sprintf("%d", fieldValue.Int())
-		default:
-			key = fmt.Sprintf("%v", fieldValue.Interface())
-		}
-
 		// Initialize group if it doesn't exist
 		if _, exists := grouped[key]; !exists {
 			grouped[key] = Alerts{Alerts: []Alert{}}
@@ -55,26 +95,27 @@ func (alerts Alerts) Group(field string) map[string]Alerts {
 }
 
 // PrettyPrintGrouped prints grouped alerts in a nice format
-func prettyPrintGrouped(grouped map[string]Alerts, groupName string) {
-	fmt.Printf("📊 Alerts grouped by %s:\n", groupName)
-	fmt.Println(strings.Repeat("=", 60))
+func prettyPrintGrouped(w io.Writer, grouped map[string]Alerts, groupName string) {
+	fmt.Fprintf(w, "📊 Alerts grouped by %s:\n", groupName)
+	fmt.Fprintln(w, strings.Repeat("=", 60))
 
 	for key, alertGroup := range grouped {
-		fmt.Printf("\n🏷️  %s: %d alerts\n", key, len(alertGroup.Alerts))
-		fmt.Println(strings.Repeat("-", 40))
+		fmt.Fprintf(w, "\n🏷️  %s: %d alerts\n", key, len(alertGroup.Alerts))
+		fmt.Fprintln(w, strings.Repeat("-", 40))
 
 		for i, alert := range alertGroup.Alerts {
-			fmt.Printf("  [%d] %s - %s (%s)\n",
+			fmt.Fprintf(w, "  [%d] %s - %s (%s)\n",
 				i+1, alert.ID, alert.Description, alert.Severity)
 		}
 	}
 
-	fmt.Printf("\n📈 Total groups: %d\n", len(grouped))
+	fmt.Fprintf(w, "\n📈 Total groups: %d\n", len(grouped))
 }
 
 // PrettyPrintGroupedBy groups alerts by the specified field and prints them
-func (alerts Alerts) PrettyPrintGroupedBy(field string) {
+// to w
+func (alerts Alerts) PrettyPrintGroupedBy(w io.Writer, field string) {
 	caser := cases.Title(language.English)
 	grouped := alerts.Group(field)
-	prettyPrintGrouped(grouped, caser.String(field))
+	prettyPrintGrouped(w, grouped, caser.String(field))
 }
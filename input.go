@@ -0,0 +1,299 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Magic bytes that identify compressed and archived input, whatever the
+// file is called
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	tarMagic  = []byte("ustar") // At offset 257 of the first header
+)
+
+// maxDecompressedSize caps how large a compressed file, or the compressed
+// entries of an archive together, may grow when decompressed, so a small
+// file can't exhaust memory
+var maxDecompressedSize int64 = 1 << 30
+
+// inputDocument is one JSON document of an input file: the file itself, or
+// an entry of an archive
+type inputDocument struct {
+	Name string // The file name, or archive:entry for entries of an archive
+	Data []byte
+}
+
+// readInputDocuments reads an input file, decompressing gzip and zstd. A tar
+// archive, compressed or not, gives one document per JSON entry.
+func readInputDocuments(filename string) ([]inputDocument, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file '%s': %v", filename, err)
+	}
+	if data, err = decompress(data, maxDecompressedSize); err != nil {
+		return nil, fmt.Errorf("error decompressing file '%s': %v", filename, err)
+	}
+
+	if !isTar(data) {
+		return []inputDocument{{Name: filename, Data: data}}, nil
+	}
+	documents, err := readTar(filename, data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading archive '%s': %v", filename, err)
+	}
+	return documents, nil
+}
+
+// decompress returns data decompressed if it starts with the gzip or zstd
+// magic bytes, and as it is otherwise. Data that decompresses to more than
+// limit bytes is an error.
+func decompress(data []byte, limit int64) ([]byte, error) {
+	tooLarge := fmt.Errorf("more than %d bytes decompressed", limit)
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		decompressed, err := io.ReadAll(io.LimitReader(reader, limit+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(decompressed)) > limit {
+			return nil, tooLarge
+		}
+		return decompressed, nil
+	case bytes.HasPrefix(data, zstdMagic):
+		if limit <= 0 {
+			return nil, tooLarge
+		}
+		decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(limit)))
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		decompressed, err := decoder.DecodeAll(data, nil)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+			return nil, tooLarge
+		}
+		return decompressed, err
+	}
+	return data, nil
+}
+
+func isTar(data []byte) bool {
+	return len(data) >= 262 && bytes.Equal(data[257:262], tarMagic)
+}
+
+// readTar returns the JSON entries of a tar archive in archive order. Entries
+// may be compressed themselves, e.g. hour-01.json.gz. Other entries, such as
+// directories and hidden files, are skipped.
+func readTar(filename string, data []byte) ([]inputDocument, error) {
+	var documents []inputDocument
+	remaining := maxDecompressedSize
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !isJSONEntry(header) {
+			continue
+		}
+
+		name := filename + ":" + header.Name
+		entry, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error reading '%s': %v", name, err)
+		}
+		if entry, err = decompress(entry, remaining); err != nil {
+			return nil, fmt.Errorf("error decompressing '%s': %v", name, err)
+		}
+		remaining -= int64(len(entry))
+		documents = append(documents, inputDocument{Name: name, Data: entry})
+	}
+
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("no JSON files in the archive")
+	}
+	return documents, nil
+}
+
+// isJSONEntry reports whether an archive entry is a JSON file, possibly
+// compressed
+func isJSONEntry(header *tar.Header) bool {
+	if header.Typeflag != tar.TypeReg {
+		return false
+	}
+	name := path.Base(header.Name)
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	name = strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(name), ".gz"), ".zst")
+	return strings.HasSuffix(name, ".json")
+}
+
+// defaultIncludePatterns match alerts files, their rotations such as
+// alerts.json.1 or alerts.json.2.gz, and archives
+var defaultIncludePatterns = []string{"*.json", "*.json.*", "*.tar", "*.tar.*", "*.tgz"}
+
+// FileLoadResult is how reading one file of an input directory went
+type FileLoadResult struct {
+	File       string
+	Alerts     int // Alerts read, including duplicates
+	Duplicates int // Alerts dropped as already read from an earlier file
+	Err        error
+}
+
+// loadAlertsFromDirectory reads the matching files of a directory in name
+// order and merges their alerts. An alert whose ID was already read from an
+// earlier file is dropped, as rotated files overlap. Files that can't be read
+// are reported and skipped.
+func loadAlertsFromDirectory(dir string, option LoadOptions) (*Alerts, error) {
+	files, err := listInputFiles(dir, option)
+	if err != nil {
+		return nil, fmt.Errorf("error listing directory '%s': %v", dir, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no input files in directory '%s'", dir)
+	}
+
+	// Parse the files concurrently, then merge them in name order
+	read, errs := make([]*Alerts, len(files)), make([]error, len(files))
+	parallelFor(len(files), 1, func(start, end int) {
+		for i := start; i < end; i++ {
+			read[i], errs[i] = readAlerts(files[i], option)
+		}
+	})
+
+	var alerts Alerts
+	var results []FileLoadResult
+	seen := make(map[string]string) // Alert ID to the file it was read from
+	for i, file := range files {
+		result := FileLoadResult{File: file}
+		if errs[i] != nil {
+			result.Err = errs[i]
+			results = append(results, result)
+			continue
+		}
+
+		result.Alerts = len(read[i].Alerts)
+		for _, alert := range read[i].Alerts {
+			if from, exists := seen[alert.ID]; exists && from != file {
+				result.Duplicates++
+				continue
+			}
+			if alert.ID != "" {
+				seen[alert.ID] = file
+			}
+			alerts.Alerts = append(alerts.Alerts, alert)
+		}
+		results = append(results, result)
+	}
+
+	reports := option.Reports
+	if reports == nil {
+		reports = os.Stderr
+	}
+	showLoadStats(reports, dir, results)
+
+	if len(alerts.Alerts) == 0 {
+		return nil, fmt.Errorf("no alerts found in directory '%s'", dir)
+	}
+	alerts.NormalizeSeverities(option.Severities)
+	return &alerts, nil
+}
+
+// listInputFiles returns the files of a directory that match the include
+// patterns and none of the exclude patterns, in name order. Patterns match
+// the file name or its path within the directory. Hidden files and
+// directories are skipped.
+func listInputFiles(dir string, option LoadOptions) ([]string, error) {
+	include := option.Include
+	if len(include) == 0 {
+		include = defaultIncludePatterns
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if file == dir {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") || (entry.IsDir() && !option.Recursive) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relative, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+		if matchesAnyPattern(include, relative) && !matchesAnyPattern(option.Exclude, relative) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func matchesAnyPattern(patterns []string, relative string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, path.Base(relative)); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, relative); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func showLoadStats(w io.Writer, dir string, results []FileLoadResult) {
+	var alerts, duplicates, failed int
+	for _, result := range results {
+		alerts += result.Alerts - result.Duplicates
+		duplicates += result.Duplicates
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	fmt.Fprintf(w, "📂 %s: %d files, %d alerts, %d duplicates dropped, %d files failed\n",
+		dir, len(results), alerts, duplicates, failed)
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(w, "  ❌ %s: %v\n", result.File, result.Err)
+		case result.Duplicates > 0:
+			fmt.Fprintf(w, "  ✅ %s: %d alerts (%d duplicates)\n", result.File, result.Alerts, result.Duplicates)
+		default:
+			fmt.Fprintf(w, "  ✅ %s: %d alerts\n", result.File, result.Alerts)
+		}
+	}
+}
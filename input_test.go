@@ -0,0 +1,256 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const hourOneJSON = `{"alerts": [
+	{"id": "ALT-1", "timestamp": "2025-04-22T09:10:00Z", "service": "web", "severity": "critical", "metric": "cpu", "value": 95, "threshold": 80}
+]}`
+
+const hourTwoJSON = `{"alerts": [
+	{"id": "ALT-2", "timestamp": "2025-04-22T10:10:00Z", "service": "db", "severity": "warning", "metric": "disk", "value": 85, "threshold": 80},
+	{"id": "ALT-3", "timestamp": "2025-04-22T10:20:00Z", "service": "db", "severity": "info", "metric": "disk", "value": 81, "threshold": 80}
+]}`
+
+func gzipData(t *testing.T, data string) string {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	return buf.String()
+}
+
+func zstdData(t *testing.T, data string) string {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	return string(encoder.EncodeAll([]byte(data), nil))
+}
+
+// tarData archives entries given as name and content pairs. Names ending in
+// a slash are directories.
+func tarData(t *testing.T, entries ...string) string {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for i := 0; i < len(entries); i += 2 {
+		header := &tar.Header{Name: entries[i], Mode: 0644, Size: int64(len(entries[i+1])), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(entries[i], "/") {
+			header.Typeflag, header.Mode = tar.TypeDir, 0755
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := writer.Write([]byte(entries[i+1])); err != nil {
+			t.Fatalf("Failed to write tar entry: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close tar: %v", err)
+	}
+	return buf.String()
+}
+
+func TestLoadCompressedAlerts(t *testing.T) {
+	for name, content := range map[string]string{"gzip": gzipData(t, hourTwoJSON), "zstd": zstdData(t, hourTwoJSON)} {
+		testFile := createTestFile(t, content)
+		defer os.Remove(testFile)
+
+		alerts, err := loadAlertsFromFile(testFile)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", name, err)
+			continue
+		}
+		if len(alerts.Alerts) != 2 || alerts.Alerts[0].ID != "ALT-2" {
+			t.Errorf("Expected the 2 alerts of the %s file, got %+v", name, alerts.Alerts)
+		}
+	}
+}
+
+func TestLoadCompressedAlertsLimit(t *testing.T) {
+	defer func(limit int64) { maxDecompressedSize = limit }(maxDecompressedSize)
+	maxDecompressedSize = int64(len(hourTwoJSON)) + 100
+	large := hourTwoJSON + strings.Repeat(" ", 1000)
+
+	for name, content := range map[string]string{
+		"gzip":    gzipData(t, large),
+		"zstd":    zstdData(t, large),
+		"archive": tarData(t, "hour-10.json.gz", gzipData(t, hourTwoJSON), "hour-11.json.zst", zstdData(t, hourTwoJSON)),
+	} {
+		testFile := createTestFile(t, content)
+		defer os.Remove(testFile)
+
+		if _, err := loadAlertsFromFile(testFile); err == nil || !strings.Contains(err.Error(), "bytes decompressed") {
+			t.Errorf("Expected a size error for %s, got %v", name, err)
+		}
+	}
+
+	small := createTestFile(t, gzipData(t, hourTwoJSON))
+	defer os.Remove(small)
+	if _, err := loadAlertsFromFile(small); err != nil {
+		t.Errorf("Expected a file under the limit to load, got %v", err)
+	}
+}
+
+func TestLoadArchivedAlerts(t *testing.T) {
+	archive := tarData(t,
+		"2025-04-22/", "",
+		"2025-04-22/hour-09.json", hourOneJSON,
+		"2025-04-22/._hour-09.json", "not json",
+		"2025-04-22/README.txt", "not json either",
+		"2025-04-22/hour-10.json.zst", zstdData(t, hourTwoJSON),
+	)
+	testFile := createTestFile(t, gzipData(t, archive))
+	defer os.Remove(testFile)
+
+	alerts, err := loadAlertsFromFile(testFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(alerts.Alerts) != 3 || alerts.Alerts[0].ID != "ALT-1" || alerts.Alerts[2].ID != "ALT-3" {
+		t.Errorf("Expected the alerts of both hours in archive order, got %+v", alerts.Alerts)
+	}
+
+	errs, err := validateFileSchema(testFile, LoadOptions{})
+	if err != nil || len(errs) != 0 {
+		t.Errorf("Expected the archive to match the schema, got %v, %v", errs, err)
+	}
+}
+
+func TestLoadArchiveErrors(t *testing.T) {
+	broken := createTestFile(t, tarData(t, "hour-09.json", hourOneJSON, "hour-10.json", `{"alerts": [`))
+	defer os.Remove(broken)
+	if _, err := loadAlertsFromFile(broken); err == nil || !strings.Contains(err.Error(), broken+":hour-10.json") {
+		t.Errorf("Expected an error naming the broken entry, got %v", err)
+	}
+
+	invalid := createTestFile(t, tarData(t, "hour-09.json", `{"alerts": [{"id": "ALT-1"}]}`))
+	defer os.Remove(invalid)
+	errs, err := validateFileSchema(invalid, LoadOptions{})
+	if err != nil || len(errs) == 0 || !strings.HasPrefix(errs[0].Pointer, "hour-09.json:/alerts/0") {
+		t.Errorf("Expected schema errors located in the entry, got %v, %v", errs, err)
+	}
+
+	empty := createTestFile(t, tarData(t, "notes.txt", "no alerts here"))
+	defer os.Remove(empty)
+	if _, err := loadAlertsFromFile(empty); err == nil || !strings.Contains(err.Error(), "no JSON files") {
+		t.Errorf("Expected an error for an archive without JSON files, got %v", err)
+	}
+
+	corrupt := createTestFile(t, gzipData(t, hourOneJSON)[:20])
+	defer os.Remove(corrupt)
+	if _, err := loadAlertsFromFile(corrupt); err == nil || !strings.Contains(err.Error(), "decompressing") {
+		t.Errorf("Expected a decompression error, got %v", err)
+	}
+}
+
+func TestLoadAlertsFromDirectory(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test_alerts_dir_*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	rotatedJSON := `{"alerts": [
+		{"id": "ALT-1", "timestamp": "2025-04-22T09:10:00Z", "service": "web", "severity": "critical"},
+		{"id": "ALT-0", "timestamp": "2025-04-22T08:10:00Z", "service": "web", "severity": "info"}
+	]}`
+	files := map[string]string{
+		"alerts.json":          hourOneJSON,
+		"alerts.json.1.gz":     gzipData(t, rotatedJSON),
+		"broken.json":          `{"alerts": [`,
+		"notes.txt":            "not alerts",
+		".alerts.json.swp":     "editor swap file",
+		"2025-04-22/hour.json": hourTwoJSON,
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	var reports bytes.Buffer
+	alerts, err := loadAlertsFromFile(dir, LoadOptions{Reports: &reports})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(alerts.Alerts) != 2 || alerts.Alerts[0].ID != "ALT-1" || alerts.Alerts[1].ID != "ALT-0" {
+		t.Errorf("Expected ALT-1 once and ALT-0 from the top directory, got %+v", alerts.Alerts)
+	}
+	for _, expected := range []string{"3 files, 2 alerts, 1 duplicates dropped, 1 files failed", "alerts.json.1.gz: 2 alerts (1 duplicates)", "❌ " + filepath.Join(dir, "broken.json")} {
+		if !strings.Contains(reports.String(), expected) {
+			t.Errorf("Expected the load stats to contain %q, got:\n%s", expected, reports.String())
+		}
+	}
+
+	reports.Reset()
+	alerts, err = loadAlertsFromFile(dir, LoadOptions{Recursive: true, Exclude: []string{"broken.*", "alerts.json.*"}, Reports: &reports})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(alerts.Alerts) != 3 || strings.Contains(reports.String(), "❌") {
+		t.Errorf("Expected the alerts of alerts.json and the subdirectory, got %+v\n%s", alerts.Alerts, reports.String())
+	}
+
+	if _, err := loadAlertsFromFile(dir, LoadOptions{Include: []string{"*.yaml"}, Reports: &reports}); err == nil {
+		t.Error("Expected an error when no file matches")
+	}
+}
+
+func BenchmarkLoadDirectory(b *testing.B) {
+	dir, err := os.MkdirTemp("", "bench_alerts_dir_*")
+	if err != nil {
+		b.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	alerts := generateAlerts(160000)
+	for i := 0; i < 16; i++ {
+		data, err := json.Marshal(Alerts{Alerts: alerts.Alerts[i*10000 : (i+1)*10000]})
+		if err != nil {
+			b.Fatalf("Failed to encode alerts: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("hour-%02d.json", i)), data, 0644); err != nil {
+			b.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	options := LoadOptions{Reports: io.Discard}
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := loadAlertsFromFile(dir, options); err != nil {
+				b.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	})
+	b.Run("one-worker", func(b *testing.B) {
+		defer func(workers int) { maxWorkers = workers }(maxWorkers)
+		maxWorkers = 1
+		for i := 0; i < b.N; i++ {
+			if _, err := loadAlertsFromFile(dir, options); err != nil {
+				b.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	})
+}
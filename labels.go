@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Prefixes of the fields that address a label or annotation, e.g. labels.region
+const (
+	labelsPrefix      = "labels."
+	annotationsPrefix = "annotations."
+)
+
+// annotationKeys are extra input keys kept as annotations rather than labels,
+// as they describe an alert instead of identifying it. Keys ending in _url
+// are annotations too.
+var annotationKeys = []string{"summary", "runbook", "dashboard"}
+
+// alertKeys are the lowercased JSON keys of the Alert input fields.
+// encoding/json matches keys case-insensitively, so any case of these is not
+// an extra key. The keys of calculated fields, such as flapping or
+// routed_team, are kept as labels instead; only Priority is dropped, as
+// every command calculates it again.
+var alertKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(Alert{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, input := field.Tag.Lookup("schema"); !field.IsExported() || !input && field.Name != "Priority" {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		keys[strings.ToLower(name)] = true
+	}
+	return keys
+}()
+
+// calculatedFields are the indexes of the exported Alert fields without a
+// schema tag: priority, maintenance, routing, detection and anomaly results.
+// They are written to output but never read from input, so a file can't
+// carry its own scores or flags.
+var calculatedFields = func() []int {
+	var fields []int
+	t := reflect.TypeOf(Alert{})
+	for i := 0; i < t.NumField(); i++ {
+		if _, input := t.Field(i).Tag.Lookup("schema"); t.Field(i).IsExported() && !input {
+			fields = append(fields, i)
+		}
+	}
+	return fields
+}()
+
+// UnmarshalJSON reads an alert, keeping keys that are not alert fields as
+// labels or annotations instead of dropping them, and leaving calculated
+// fields unset. Explicit "labels" and
+// "annotations" objects win over extra keys of the same name. Timestamps may
+// be in any format parseTimestamp accepts.
+func (alert *Alert) UnmarshalJSON(data []byte) error {
+	type plainAlert Alert // Without this method, to avoid recursion
+	aux := struct {
+		*plainAlert
+		Timestamp json.RawMessage `json:"timestamp"` // Read by parseTimestamp, which accepts more formats
+	}{plainAlert: (*plainAlert)(alert)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	value := reflect.ValueOf(alert).Elem()
+	for _, i := range calculatedFields {
+		value.Field(i).SetZero()
+	}
+	if len(aux.Timestamp) > 0 {
+		timestamp, err := unmarshalTimestamp(aux.Timestamp)
+		if err != nil {
+			if alert.ID != "" {
+				return fmt.Errorf("alert '%s': %v", alert.ID, err)
+			}
+			return err
+		}
+		alert.Timestamp = timestamp
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for key, value := range raw {
+		if alertKeys[strings.ToLower(key)] {
+			continue
+		}
+		text, ok := extraValue(value)
+		if !ok {
+			continue
+		}
+
+		target := &alert.Labels
+		if isAnnotationKey(key) {
+			target = &alert.Annotations
+		}
+		if *target == nil {
+			*target = make(map[string]string)
+		}
+		if _, exists := (*target)[key]; !exists {
+			(*target)[key] = text
+		}
+	}
+	return nil
+}
+
+// extraValue returns the string form of an extra key's value. Numbers and
+// booleans keep their JSON text; null is skipped.
+func extraValue(value json.RawMessage) (string, bool) {
+	if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+		return "", false
+	}
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		return text, true
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, value); err != nil {
+		return "", false
+	}
+	return compact.String(), true
+}
+
+func isAnnotationKey(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasSuffix(key, "_url") || contains(annotationKeys, key)
+}
+
+// labelField splits a field such as labels.region into its map and key
+func labelField(alert Alert, field string) (map[string]string, string, bool) {
+	switch {
+	case len(field) > len(labelsPrefix) && strings.EqualFold(field[:len(labelsPrefix)], labelsPrefix):
+		return alert.Labels, field[len(labelsPrefix):], true
+	case len(field) > len(annotationsPrefix) && strings.EqualFold(field[:len(annotationsPrefix)], annotationsPrefix):
+		return alert.Annotations, field[len(annotationsPrefix):], true
+	}
+	return nil, "", false
+}
+
+// formatLabels formats labels as sorted key=value pairs
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAlertUnmarshalExtraKeys(t *testing.T) {
+	data := `{
+		"id": "ALT-1", "Service": "web", "severity": "critical", "value": 95,
+		"region": "eu-west-1", "cluster": "prod-a", "replicas": 3, "canary": false, "owner": null,
+		"runbook_url": "https://runbooks/web", "summary": "Web is down",
+		"labels": {"cluster": "prod-b"}
+	}`
+
+	var alert Alert
+	if err := json.Unmarshal([]byte(data), &alert); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if alert.Service != "web" || alert.Value != 95 {
+		t.Errorf("Expected the alert fields to be read, got %+v", alert)
+	}
+	expectedLabels := map[string]string{"region": "eu-west-1", "cluster": "prod-b", "replicas": "3", "canary": "false"}
+	if len(alert.Labels) != len(expectedLabels) {
+		t.Errorf("Expected labels %v, got %v", expectedLabels, alert.Labels)
+	}
+	for key, value := range expectedLabels {
+		if alert.Labels[key] != value {
+			t.Errorf("Expected label %s=%s, got %q", key, value, alert.Labels[key])
+		}
+	}
+	if alert.Annotations["runbook_url"] != "https://runbooks/web" || alert.Annotations["summary"] != "Web is down" {
+		t.Errorf("Expected runbook_url and summary as annotations, got %v", alert.Annotations)
+	}
+}
+
+func TestAlertJSONRoundTrip(t *testing.T) {
+	alert := Alert{ID: "ALT-1", Service: "web", Priority: 12, Team: "sre",
+		Labels: map[string]string{"region": "eu"}, Annotations: map[string]string{"runbook_url": "https://runbooks/web"}}
+
+	data, err := json.Marshal(alert)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded Alert
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(decoded.Labels) != 2 || decoded.Labels["region"] != "eu" || decoded.Labels["routed_team"] != "sre" {
+		t.Errorf("Expected the region label and the routed team as a label after a round trip, got %v", decoded.Labels)
+	}
+	if len(decoded.Annotations) != 1 || decoded.Priority != 0 || decoded.Team != "" {
+		t.Errorf("Expected annotations to survive a round trip and calculated fields not to be read, got %+v", decoded)
+	}
+}
+
+func TestAlertJSONIgnoresAnomalyScore(t *testing.T) {
+	var alerts Alerts
+	data := `{"alerts": [
+		{"id": "ALT-1", "service": "web", "severity": "info", "value": 10, "threshold": 10, "anomaly_score": 500},
+		{"id": "ALT-2", "service": "api", "severity": "info", "value": 10, "threshold": 10}
+	]}`
+	if err := json.Unmarshal([]byte(data), &alerts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	alerts.CalculateAllPriorities()
+
+	scored, plain := alerts.Alerts[0], alerts.Alerts[1]
+	if scored.AnomalyScore != 0 || scored.Priority != plain.Priority {
+		t.Errorf("Expected the anomaly score in the input to be ignored, got score %.2f and priority %.2f instead of %.2f",
+			scored.AnomalyScore, scored.Priority, plain.Priority)
+	}
+}
+
+func TestAlertJSONKeepsCalculatedKeysAsLabels(t *testing.T) {
+	var alerts Alerts
+	data := `{"alerts": [{"id": "ALT-1", "service": "web", "flapping": true, "flap_count": 7, "storm": true,
+		"maintenance": "deploy", "routed_team": "sre", "on_call": "alice"}]}`
+	if err := json.Unmarshal([]byte(data), &alerts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	alert := alerts.Alerts[0]
+	if alert.Flapping || alert.FlapCount != 0 || alert.Storm || alert.Maintenance != "" || alert.Team != "" || alert.OnCall != "" {
+		t.Errorf("Expected calculated fields not to be read from input, got %+v", alert)
+	}
+	for key, value := range map[string]string{"flapping": "true", "flap_count": "7", "storm": "true", "maintenance": "deploy", "routed_team": "sre", "on_call": "alice"} {
+		if alert.Labels[key] != value {
+			t.Errorf("Expected label %s=%s, got %q", key, value, alert.Labels[key])
+		}
+	}
+	if flapping := alerts.FilterByDetection("flapping"); len(flapping.Alerts) != 0 {
+		t.Errorf("Expected a flapping key in the input not to pass the flapping filter, got %+v", flapping.Alerts)
+	}
+}
+
+func TestLabelFields(t *testing.T) {
+	alerts := Alerts{Alerts: []Alert{
+		{ID: "ALT-1", Service: "web", Labels: map[string]string{"region": "eu-west-1", "Cluster": "a"}},
+		{ID: "ALT-2", Service: "api", Labels: map[string]string{"region": "us-east-1"}},
+		{ID: "ALT-3", Service: "db", Labels: map[string]string{"region": "eu-central-1"}},
+		{ID: "ALT-4", Service: "cache"},
+	}}
+
+	if value, ok := alertFieldValue(alerts.Alerts[0], "Labels.Cluster"); !ok || value != "a" {
+		t.Errorf("Expected label names to be case-sensitive and the prefix not, got %q, %v", value, ok)
+	}
+	if _, ok := alertFieldValue(alerts.Alerts[3], "labels.region"); ok {
+		t.Error("Expected no value for a missing label")
+	}
+
+	grouped := alerts.Group("labels.region")
+	if len(grouped) != 3 || len(grouped["eu-west-1"].Alerts) != 1 {
+		t.Errorf("Expected 3 region groups without the unlabelled alert, got %v", grouped)
+	}
+
+	filters, err := parseFieldFilters("labels.region=eu-*, service=web|db")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	filtered := alerts.FilterByFields(filters)
+	if len(filtered.Alerts) != 2 || filtered.Alerts[0].ID != "ALT-1" || filtered.Alerts[1].ID != "ALT-3" {
+		t.Errorf("Expected ALT-1 and ALT-3 to match, got %+v", filtered.Alerts)
+	}
+
+	for _, spec := range []string{"region=eu", "labels.=eu", "labels.region", "labels.region=[eu"} {
+		if _, err := parseFieldFilters(spec); err == nil {
+			t.Errorf("Expected an error for filter %q", spec)
+		}
+	}
+
+	route := &Route{Receiver: "default", Routes: []*Route{
+		{Receiver: "eu-team", Match: map[string]string{"labels.region": "eu-*"}},
+	}}
+	if err := route.validate(); err != nil {
+		t.Fatalf("Expected label matchers to be valid, got %v", err)
+	}
+	if receivers := route.Receivers(alerts.Alerts[2]); len(receivers) != 1 || receivers[0] != "eu-team" {
+		t.Errorf("Expected the EU alert to be routed to eu-team, got %v", receivers)
+	}
+}
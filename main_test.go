@@ -61,19 +61,19 @@ func TestFilterBySeverity(t *testing.T) {
 	alerts := createTestAlerts()
 	
 	// Test filtering by critical severity
-	criticalAlerts := alerts.FilterBySeverity("critical")
+	criticalAlerts := alerts.FilterBySeverity(defaultSeverities, "critical")
 	if len(criticalAlerts.Alerts) != 2 {
 		t.Errorf("Expected 2 critical alerts, got %d", len(criticalAlerts.Alerts))
 	}
 	
 	// Test filtering by warning severity
-	warningAlerts := alerts.FilterBySeverity("warning")
+	warningAlerts := alerts.FilterBySeverity(defaultSeverities, "warning")
 	if len(warningAlerts.Alerts) != 1 {
 		t.Errorf("Expected 1 warning alert, got %d", len(warningAlerts.Alerts))
 	}
 	
 	// Test filtering by non-existent severity
-	noneAlerts := alerts.FilterBySeverity("nonexistent")
+	noneAlerts := alerts.FilterBySeverity(defaultSeverities, "nonexistent")
 	if len(noneAlerts.Alerts) != 0 {
 		t.Errorf("Expected 0 alerts for non-existent severity, got %d", len(noneAlerts.Alerts))
 	}
@@ -321,7 +321,7 @@ func BenchmarkFilterBySeverity(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		alerts.FilterBySeverity("critical")
+		alerts.FilterBySeverity(defaultSeverities, "critical")
 	}
 }
 
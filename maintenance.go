@@ -0,0 +1,419 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindows represents a collection of maintenance windows
+type MaintenanceWindows struct {
+	Windows []MaintenanceWindow `json:"windows"`
+}
+
+// MaintenanceWindow describes a period during which alerts for matching
+// services are suppressed or down-weighted. A window is either weekly
+// (days + start/end), cron-style (cron + duration) or a one-off silence
+// (starts_at/ends_at).
+type MaintenanceWindow struct {
+	Name     string   `json:"name"`
+	Services []string `json:"services"` // Glob patterns, empty matches every service
+
+	Days  []string `json:"days,omitempty"`  // Weekly schedule, e.g. ["sunday"]
+	Start string   `json:"start,omitempty"` // Weekly schedule start, "02:00"
+	End   string   `json:"end,omitempty"`   // Weekly schedule end, "04:00"
+
+	Cron     string `json:"cron,omitempty"`     // "minute hour day-of-month month day-of-week"
+	Duration string `json:"duration,omitempty"` // How long the window stays open after each cron match
+
+	StartsAt time.Time `json:"starts_at,omitempty"` // One-off silence start
+	EndsAt   time.Time `json:"ends_at,omitempty"`   // One-off silence end
+
+	Timezone string  `json:"timezone,omitempty"` // IANA zone for the schedule, defaults to UTC
+	Action   string  `json:"action,omitempty"`   // "suppress" (default) or "downweight"
+	Factor   float64 `json:"factor,omitempty"`   // Priority multiplier for "downweight", defaults to 0.5
+
+	schedule *cronSchedule
+	duration time.Duration
+	location *time.Location
+}
+
+// MaintenanceResult reports how many alerts a window affected
+type MaintenanceResult struct {
+	Window       string
+	Action       string
+	Suppressed   int
+	Downweighted int
+}
+
+const (
+	maintenanceSuppress   = "suppress"
+	maintenanceDownweight = "downweight"
+)
+
+func loadMaintenanceWindows(filename string) ([]MaintenanceWindow, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading maintenance file '%s': %v", filename, err)
+	}
+
+	var windows MaintenanceWindows
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return nil, fmt.Errorf("error parsing maintenance windows from '%s': %v", filename, err)
+	}
+
+	for i := range windows.Windows {
+		if err := windows.Windows[i].compile(); err != nil {
+			return nil, fmt.Errorf("invalid maintenance window '%s' in '%s': %v",
+				windows.Windows[i].Name, filename, err)
+		}
+	}
+
+	return windows.Windows, nil
+}
+
+// compile validates the window and prepares its schedule for matching
+func (w *MaintenanceWindow) compile() error {
+	w.location = time.UTC
+	if w.Timezone != "" {
+		loc, err := time.LoadLocation(w.Timezone)
+		if err != nil {
+			return fmt.Errorf("unknown timezone '%s'", w.Timezone)
+		}
+		w.location = loc
+	}
+
+	switch strings.ToLower(w.Action) {
+	case "", maintenanceSuppress:
+		w.Action = maintenanceSuppress
+	case maintenanceDownweight:
+		w.Action = maintenanceDownweight
+		if w.Factor == 0 {
+			w.Factor = 0.5
+		}
+		if w.Factor < 0 || w.Factor > 1 {
+			return fmt.Errorf("factor must be between 0 and 1")
+		}
+	default:
+		return fmt.Errorf("invalid action '%s'. Valid actions: suppress, downweight", w.Action)
+	}
+
+	for _, pattern := range w.Services {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid service pattern '%s'", pattern)
+		}
+	}
+
+	switch {
+	case !w.StartsAt.IsZero() || !w.EndsAt.IsZero():
+		if w.StartsAt.IsZero() || !w.EndsAt.After(w.StartsAt) {
+			return fmt.Errorf("one-off silences need starts_at before ends_at")
+		}
+		return nil
+	case w.Cron != "":
+		schedule, err := parseCron(w.Cron)
+		if err != nil {
+			return err
+		}
+		duration, err := time.ParseDuration(w.Duration)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("cron windows need a positive duration, got '%s'", w.Duration)
+		}
+		w.schedule = schedule
+		w.duration = duration
+		return nil
+	case len(w.Days) > 0:
+		return w.compileWeekly()
+	default:
+		return fmt.Errorf("window needs days/start/end, cron/duration or starts_at/ends_at")
+	}
+}
+
+// compileWeekly turns a weekly schedule into the equivalent cron schedule
+func (w *MaintenanceWindow) compileWeekly() error {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return fmt.Errorf("invalid start '%s': %v", w.Start, err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return fmt.Errorf("invalid end '%s': %v", w.End, err)
+	}
+
+	// Windows ending at or before their start wrap past midnight
+	duration := end - start
+	if duration <= 0 {
+		duration += 24 * time.Hour
+	}
+
+	minutes := int(start.Minutes())
+	spec := fmt.Sprintf("%d %d * * %s", minutes%60, minutes/60, strings.Join(w.Days, ","))
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return err
+	}
+
+	w.schedule = schedule
+	w.duration = duration
+	return nil
+}
+
+// parseClock parses "HH:MM" into an offset from midnight
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM")
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// MatchesService reports whether the window applies to the given service
+func (w MaintenanceWindow) MatchesService(service string) bool {
+	if len(w.Services) == 0 {
+		return true
+	}
+	for _, pattern := range w.Services {
+		if ok, _ := path.Match(pattern, service); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the window is open at the given time
+func (w MaintenanceWindow) Active(t time.Time) bool {
+	if !w.StartsAt.IsZero() {
+		return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+	}
+	if w.schedule == nil {
+		return false
+	}
+
+	// The window is open if the schedule last fired within `duration`
+	_, fired := w.schedule.previous(t.In(w.location), w.duration)
+	return fired
+}
+
+// ApplyMaintenance removes alerts that fall inside a suppressing window and
+// down-weights alerts inside a down-weighting window. The first matching
+// window wins. Only windows that affected at least one alert are reported.
+func (alerts Alerts) ApplyMaintenance(windows []MaintenanceWindow) (Alerts, []MaintenanceResult) {
+	var remaining []Alert
+	results := make([]MaintenanceResult, len(windows))
+	for i, window := range windows {
+		results[i] = MaintenanceResult{Window: window.Name, Action: window.Action}
+	}
+
+	// Alerts tend to share minutes, so remember whether each window was
+	// open in a minute instead of searching its schedule for every alert
+	open := make([]map[int64]bool, len(windows))
+	for i := range open {
+		open[i] = make(map[int64]bool)
+	}
+	activeAt := func(i int, t time.Time) bool {
+		if windows[i].schedule == nil || !windows[i].StartsAt.IsZero() {
+			return windows[i].Active(t)
+		}
+		minute := t.Unix() / 60
+		isOpen, found := open[i][minute]
+		if !found {
+			isOpen = windows[i].Active(t)
+			open[i][minute] = isOpen
+		}
+		return isOpen
+	}
+
+	for _, alert := range alerts.Alerts {
+		suppressed := false
+		for i, window := range windows {
+			if !window.MatchesService(alert.Service) || !activeAt(i, alert.Timestamp) {
+				continue
+			}
+			if window.Action == maintenanceDownweight {
+				alert.Maintenance = window.Name
+				alert.maintenanceFactor = window.Factor
+				results[i].Downweighted++
+			} else {
+				suppressed = true
+				results[i].Suppressed++
+			}
+			break
+		}
+		if !suppressed {
+			remaining = append(remaining, alert)
+		}
+	}
+
+	var active []MaintenanceResult
+	for _, result := range results {
+		if result.Suppressed > 0 || result.Downweighted > 0 {
+			active = append(active, result)
+		}
+	}
+
+	return Alerts{Alerts: remaining}, active
+}
+
+func showMaintenanceReport(w io.Writer, results []MaintenanceResult) {
+	if len(results) == 0 {
+		fmt.Fprintln(w, "🛠️  No maintenance windows were active for these alerts")
+		return
+	}
+
+	fmt.Fprintln(w, "🛠️  Active maintenance windows:")
+	for _, result := range results {
+		if result.Action == maintenanceDownweight {
+			fmt.Fprintf(w, "  %s: %d alerts down-weighted\n", result.Window, result.Downweighted)
+		} else {
+			fmt.Fprintf(w, "  %s: %d alerts suppressed\n", result.Window, result.Suppressed)
+		}
+	}
+}
+
+// cronSchedule is a parsed five-field cron expression
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	daysRestricted, weekdaysRestricted     bool
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	"sunday": 0, "monday": 1, "tuesday": 2, "wednesday": 3, "thursday": 4, "friday": 5, "saturday": 6,
+}
+
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' must have 5 fields", spec)
+	}
+
+	var err error
+	schedule := &cronSchedule{
+		daysRestricted:     fields[2] != "*",
+		weekdaysRestricted: fields[4] != "*",
+	}
+	if schedule.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron minute: %v", err)
+	}
+	if schedule.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron hour: %v", err)
+	}
+	if schedule.days, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron day of month: %v", err)
+	}
+	if schedule.months, err = parseCronField(fields[3], 1, 12, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron month: %v", err)
+	}
+	if schedule.weekdays, err = parseCronField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, fmt.Errorf("invalid cron day of week: %v", err)
+	}
+	// 7 is an alias for Sunday
+	if schedule.weekdays[7] {
+		schedule.weekdays[0] = true
+	}
+
+	return schedule, nil
+}
+
+// parseCronField parses lists, ranges and steps such as "1-5", "*/15", "5/15" or "mon,wed"
+func parseCronField(field string, min, max int, names map[string]int) (map[int]bool, error) {
+	values := make(map[int]bool)
+
+	parseValue := func(s string) (int, error) {
+		if n, ok := names[strings.ToLower(s)]; ok {
+			return n, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("'%s' is not in range %d-%d", s, min, max)
+		}
+		return n, nil
+	}
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		base, stepStr, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step '%s'", stepStr)
+			}
+			part, step = base, n
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			loStr, hiStr, isRange := strings.Cut(part, "-")
+			var err error
+			if lo, err = parseValue(loStr); err != nil {
+				return nil, err
+			}
+			// A step from a single value runs to the end of the range: 5/15
+			// is 5,20,35,50
+			hi = lo
+			if hasStep {
+				hi = max
+			}
+			if isRange {
+				if hi, err = parseValue(hiStr); err != nil {
+					return nil, err
+				}
+			}
+			if hi < lo {
+				return nil, fmt.Errorf("invalid range '%s'", part)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// matches reports whether the schedule fires at the given minute. As in
+// standard cron, a restricted day of month and day of week are OR-ed.
+func (c *cronSchedule) matches(t time.Time) bool {
+	return c.minutes[t.Minute()] && c.hours[t.Hour()] && c.matchesDay(t)
+}
+
+// matchesDay reports whether the schedule fires at some time on t's day
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	if !c.months[int(t.Month())] {
+		return false
+	}
+
+	dayMatch := c.days[t.Day()]
+	weekdayMatch := c.weekdays[int(t.Weekday())]
+	if c.daysRestricted && c.weekdaysRestricted {
+		return dayMatch || weekdayMatch
+	}
+	return dayMatch && weekdayMatch
+}
+
+// previous returns the last minute at or before t that the schedule fires,
+// looking back less than limit. Days and hours the schedule doesn't fire in
+// are skipped as a whole.
+func (c *cronSchedule) previous(t time.Time, limit time.Duration) (time.Time, bool) {
+	t = t.Truncate(time.Minute)
+	earliest := t.Add(-limit)
+	for t.After(earliest) {
+		switch {
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+		case !c.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(-time.Minute)
+		case !c.minutes[t.Minute()]:
+			t = t.Add(-time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("Failed to parse time %s: %v", s, err)
+	}
+	return parsed
+}
+
+func TestParseCron(t *testing.T) {
+	schedule, err := parseCron("*/15 2-4 * * mon,wed")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// 2024-04-29 is a Monday
+	if !schedule.matches(mustParseTime(t, "2024-04-29T03:45:00Z")) {
+		t.Error("Expected schedule to match Monday 03:45")
+	}
+	if schedule.matches(mustParseTime(t, "2024-04-29T03:40:00Z")) {
+		t.Error("Expected schedule not to match Monday 03:40")
+	}
+	if schedule.matches(mustParseTime(t, "2024-04-30T03:45:00Z")) {
+		t.Error("Expected schedule not to match Tuesday 03:45")
+	}
+
+	// A step from a single value runs to the end of the field
+	minutes, err := parseCronField("5/15", 0, 59, nil)
+	if err != nil || len(minutes) != 4 || !minutes[5] || !minutes[20] || !minutes[35] || !minutes[50] {
+		t.Errorf("Expected 5/15 to be 5,20,35,50, got %v, %v", minutes, err)
+	}
+
+	invalid := []string{"* * * *", "60 * * * *", "* * * * funday", "*/0 * * * *", "5-1 * * * *"}
+	for _, spec := range invalid {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("Expected error for cron expression '%s', got nil", spec)
+		}
+	}
+}
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	weekly := MaintenanceWindow{Name: "batch", Days: []string{"sunday"}, Start: "02:00", End: "04:00"}
+	if err := weekly.compile(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// 2024-04-28 is a Sunday
+	tests := []struct {
+		time     string
+		expected bool
+	}{
+		{"2024-04-28T02:00:00Z", true},
+		{"2024-04-28T03:59:59Z", true},
+		{"2024-04-28T04:00:00Z", false},
+		{"2024-04-28T01:59:00Z", false},
+		{"2024-04-29T03:00:00Z", false},
+	}
+	for _, test := range tests {
+		if got := weekly.Active(mustParseTime(t, test.time)); got != test.expected {
+			t.Errorf("Active(%s) = %t, expected %t", test.time, got, test.expected)
+		}
+	}
+
+	// Windows ending before they start wrap past midnight
+	overnight := MaintenanceWindow{Name: "overnight", Days: []string{"sat"}, Start: "23:00", End: "01:00"}
+	if err := overnight.compile(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !overnight.Active(mustParseTime(t, "2024-04-28T00:30:00Z")) {
+		t.Error("Expected overnight window to be active after midnight")
+	}
+
+	// Schedules are evaluated in the window's timezone
+	helsinki := MaintenanceWindow{Name: "helsinki", Cron: "0 2 * * *", Duration: "1h", Timezone: "Europe/Helsinki"}
+	if err := helsinki.compile(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !helsinki.Active(mustParseTime(t, "2024-04-28T23:30:00Z")) {
+		t.Error("Expected 02:30 Helsinki time to be inside the window")
+	}
+	if helsinki.Active(mustParseTime(t, "2024-04-28T02:30:00Z")) {
+		t.Error("Expected 02:30 UTC to be outside the Helsinki window")
+	}
+
+	silence := MaintenanceWindow{
+		Name:     "deploy",
+		StartsAt: mustParseTime(t, "2024-04-28T10:00:00Z"),
+		EndsAt:   mustParseTime(t, "2024-04-28T10:30:00Z"),
+	}
+	if err := silence.compile(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !silence.Active(mustParseTime(t, "2024-04-28T10:26:19Z")) {
+		t.Error("Expected one-off silence to be active")
+	}
+}
+
+func TestMaintenanceWindowActiveMatchesMinuteWalk(t *testing.T) {
+	windows := []MaintenanceWindow{
+		{Name: "weekly", Days: []string{"sat", "sun"}, Start: "22:00", End: "02:30"},
+		{Name: "hourly", Cron: "5/15 * * * *", Duration: "7m"},
+		{Name: "monthly", Cron: "30 1 1,15 * *", Duration: "36h", Timezone: "Europe/Helsinki"},
+		{Name: "weekdays", Cron: "0 9-17/2 * 3-4 mon-fri", Duration: "90m"},
+	}
+	start := mustParseTime(t, "2024-03-29T00:00:00Z") // Across the Helsinki DST change
+	for _, window := range windows {
+		if err := window.compile(); err != nil {
+			t.Fatalf("Expected no error for %s, got: %v", window.Name, err)
+		}
+		for at := start; at.Before(start.Add(21 * 24 * time.Hour)); at = at.Add(17*time.Minute + 13*time.Second) {
+			// Walk back minute by minute, as Active used to
+			expected := false
+			local := at.In(window.location).Truncate(time.Minute)
+			for back := time.Duration(0); back < window.duration; back += time.Minute {
+				if window.schedule.matches(local.Add(-back)) {
+					expected = true
+					break
+				}
+			}
+			if got := window.Active(at); got != expected {
+				t.Fatalf("%s: Active(%s) = %t, expected %t", window.Name, at, got, expected)
+			}
+		}
+	}
+}
+
+func BenchmarkApplyMaintenance(b *testing.B) {
+	windows := []MaintenanceWindow{{Name: "nightly", Cron: "0 3 * * *", Duration: "24h", Action: maintenanceDownweight}}
+	if err := windows[0].compile(); err != nil {
+		b.Fatalf("Expected no error, got: %v", err)
+	}
+	alerts := generateAlerts(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		alerts.ApplyMaintenance(windows)
+	}
+}
+
+func TestMaintenanceWindowCompileErrors(t *testing.T) {
+	windows := []MaintenanceWindow{
+		{Name: "no-schedule"},
+		{Name: "bad-action", Cron: "0 2 * * *", Duration: "1h", Action: "ignore"},
+		{Name: "no-duration", Cron: "0 2 * * *"},
+		{Name: "bad-clock", Days: []string{"sun"}, Start: "2am", End: "04:00"},
+		{Name: "bad-zone", Cron: "0 2 * * *", Duration: "1h", Timezone: "Mars/Olympus"},
+		{Name: "bad-factor", Cron: "0 2 * * *", Duration: "1h", Action: "downweight", Factor: 2},
+	}
+
+	for _, window := range windows {
+		if err := window.compile(); err == nil {
+			t.Errorf("Expected error for window '%s', got nil", window.Name)
+		}
+	}
+}
+
+func TestApplyMaintenance(t *testing.T) {
+	alerts := Alerts{
+		Alerts: []Alert{
+			{ID: "ALT-1", Service: "batch-processor", Severity: "critical", Timestamp: mustParseTime(t, "2024-04-28T02:30:00Z")},
+			{ID: "ALT-2", Service: "batch-processor", Severity: "critical", Timestamp: mustParseTime(t, "2024-04-28T05:30:00Z")},
+			{ID: "ALT-3", Service: "web-frontend", Severity: "critical", Timestamp: mustParseTime(t, "2024-04-28T02:30:00Z")},
+			{ID: "ALT-4", Service: "web-backend", Severity: "critical", Timestamp: mustParseTime(t, "2024-04-28T02:30:00Z")},
+		},
+	}
+
+	windows := []MaintenanceWindow{
+		{Name: "batch", Services: []string{"batch-*"}, Days: []string{"sunday"}, Start: "02:00", End: "04:00"},
+		{Name: "frontend", Services: []string{"web-frontend"}, Cron: "0 2 * * *", Duration: "2h", Action: "downweight", Factor: 0.5},
+		{Name: "unused", Services: []string{"nothing"}, Cron: "0 2 * * *", Duration: "2h"},
+	}
+	for i := range windows {
+		if err := windows[i].compile(); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	remaining, results := alerts.ApplyMaintenance(windows)
+	if len(remaining.Alerts) != 3 {
+		t.Fatalf("Expected 3 remaining alerts, got %d", len(remaining.Alerts))
+	}
+	for _, alert := range remaining.Alerts {
+		if alert.ID == "ALT-1" {
+			t.Error("Expected ALT-1 to be suppressed")
+		}
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 active windows, got %d", len(results))
+	}
+	if results[0].Window != "batch" || results[0].Suppressed != 1 {
+		t.Errorf("Expected batch window to suppress 1 alert, got %+v", results[0])
+	}
+	if results[1].Window != "frontend" || results[1].Downweighted != 1 {
+		t.Errorf("Expected frontend window to down-weight 1 alert, got %+v", results[1])
+	}
+
+	// Down-weighted alerts are scored at the window's factor
+	remaining.CalculateAllPriorities()
+	var frontend, backend Alert
+	for _, alert := range remaining.Alerts {
+		switch alert.ID {
+		case "ALT-3":
+			frontend = alert
+		case "ALT-4":
+			backend = alert
+		}
+	}
+	if frontend.Maintenance != "frontend" {
+		t.Errorf("Expected ALT-3 to be marked with the frontend window, got '%s'", frontend.Maintenance)
+	}
+	if frontend.Priority != backend.Priority*0.5 {
+		t.Errorf("Expected down-weighted priority %.2f, got %.2f", backend.Priority*0.5, frontend.Priority)
+	}
+}
+
+func TestLoadMaintenanceWindows(t *testing.T) {
+	content := `{
+  "windows": [
+    {"name": "batch", "services": ["batch-processor"], "days": ["sunday"], "start": "02:00", "end": "04:00"},
+    {"name": "nightly", "cron": "30 1 * * *", "duration": "90m", "timezone": "Europe/Helsinki", "action": "downweight"}
+  ]
+}`
+	testFile := createTestFile(t, content)
+	defer os.Remove(testFile)
+
+	windows, err := loadMaintenanceWindows(testFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("Expected 2 windows, got %d", len(windows))
+	}
+	if windows[1].Factor != 0.5 {
+		t.Errorf("Expected default down-weight factor 0.5, got %.2f", windows[1].Factor)
+	}
+
+	invalidFile := createTestFile(t, `{"windows": [{"name": "broken", "cron": "bad"}]}`)
+	defer os.Remove(invalidFile)
+
+	_, err = loadMaintenanceWindows(invalidFile)
+	if err == nil || !strings.Contains(err.Error(), "invalid maintenance window 'broken'") {
+		t.Errorf("Expected error about invalid window, got: %v", err)
+	}
+}
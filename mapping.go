@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamp formats of a field mapping. Any other format is a Go time layout.
+const (
+	timestampRFC3339 = "rfc3339"
+	timestampEpochS  = "epoch_s"
+	timestampEpochMs = "epoch_ms"
+)
+
+// FieldMapping describes how to read alerts from a foreign JSON shape
+type FieldMapping struct {
+	Alerts          string            `json:"alerts"`           // Path to the array of alerts, "$" for a top-level array; defaults to "alerts"
+	Fields          map[string]string `json:"fields"`           // Alert field (or labels.<name>) to source path within an alert
+	TimestampFormat string            `json:"timestamp_format"` // rfc3339, epoch_s, epoch_ms or a Go layout; any format the native input accepts by default
+	Severities      map[string]string `json:"severities"`       // Source severity to a severity on the ladder, e.g. "P1": "critical"
+
+	paths map[string][]pathSegment
+	root  []pathSegment
+}
+
+// pathSegment is one step of a source path: an object key, or an array index
+type pathSegment struct {
+	key   string
+	index int // -1 for object keys
+}
+
+// LoadOptions change how alerts files are read
+type LoadOptions struct {
+	Mapping    *FieldMapping  // Reads a foreign JSON shape, if set
+	Severities *SeverityModel // Severity ladder to normalize severities with, the default if unset
+	Recursive  bool           // Read the subdirectories of an input directory too
+	Include    []string       // Glob patterns of the files read from a directory, defaultIncludePatterns if unset
+	Exclude    []string       // Glob patterns of files to skip in a directory
+	Reports    io.Writer      // Where the load stats of a directory go, stderr if unset
+}
+
+// mergeLoadOptions combines load options, later ones winning
+func mergeLoadOptions(options []LoadOptions) LoadOptions {
+	var merged LoadOptions
+	for _, option := range options {
+		if option.Mapping != nil {
+			merged.Mapping = option.Mapping
+		}
+		if option.Severities != nil {
+			merged.Severities = option.Severities
+		}
+		if option.Include != nil {
+			merged.Include = option.Include
+		}
+		if option.Exclude != nil {
+			merged.Exclude = option.Exclude
+		}
+		if option.Reports != nil {
+			merged.Reports = option.Reports
+		}
+		merged.Recursive = merged.Recursive || option.Recursive
+	}
+	if merged.Severities == nil {
+		merged.Severities = defaultSeverities
+	}
+	return merged
+}
+
+func loadFieldMapping(filename string, severities *SeverityModel) (*FieldMapping, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading mapping file '%s': %v", filename, err)
+	}
+
+	var mapping FieldMapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("error parsing field mapping from '%s': %v", filename, err)
+	}
+	if err := mapping.compile(severities); err != nil {
+		return nil, fmt.Errorf("invalid field mapping in '%s': %v", filename, err)
+	}
+	return &mapping, nil
+}
+
+// compile validates the mapping against a severity ladder and parses its paths
+func (mapping *FieldMapping) compile(severities *SeverityModel) error {
+	if mapping.Alerts == "" {
+		mapping.Alerts = "alerts"
+	}
+	var err error
+	if mapping.root, err = parsePath(mapping.Alerts); err != nil {
+		return fmt.Errorf("invalid alerts path '%s': %v", mapping.Alerts, err)
+	}
+
+	inputFields := inputSchema(severities).Properties["alerts"].Items.Properties
+	mapping.paths = make(map[string][]pathSegment, len(mapping.Fields))
+	for field, source := range mapping.Fields {
+		if _, _, isLabel := labelField(Alert{}, field); !isLabel && inputFields[field] == nil {
+			return fmt.Errorf("unknown alert field '%s'", field)
+		}
+		if mapping.paths[field], err = parsePath(source); err != nil {
+			return fmt.Errorf("invalid path '%s' for field '%s': %v", source, field, err)
+		}
+		if len(mapping.paths[field]) == 0 {
+			return fmt.Errorf("empty path for field '%s'", field)
+		}
+	}
+
+	for from, to := range mapping.Severities {
+		if _, ok := severities.Rank(to); !ok {
+			return fmt.Errorf("invalid severity '%s' for '%s'. Valid severities: %s", to, from, strings.Join(severities.Names(), ", "))
+		}
+	}
+	return nil
+}
+
+// parsePath parses a JSONPath-like path such as $.data.items or tags[0].name.
+// The leading "$" is optional; "$" alone is the value itself.
+func parsePath(path string) ([]pathSegment, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var segments []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key == "" && rest == "" {
+			return nil, fmt.Errorf("empty key")
+		}
+		if key != "" {
+			segments = append(segments, pathSegment{key: key, index: -1})
+		}
+		for rest != "" {
+			number, after, found := strings.Cut(rest, "]")
+			index, err := strconv.Atoi(number)
+			if !found || err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index in '%s'", part)
+			}
+			segments = append(segments, pathSegment{index: index})
+			if after != "" && !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("unexpected '%s' after index", after)
+			}
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return segments, nil
+}
+
+// lookupPath returns the value at a path, if there is one
+func lookupPath(value interface{}, path []pathSegment) (interface{}, bool) {
+	for _, segment := range path {
+		if segment.index >= 0 {
+			items, ok := value.([]interface{})
+			if !ok || segment.index >= len(items) {
+				return nil, false
+			}
+			value = items[segment.index]
+			continue
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[segment.key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Apply converts a foreign document to the alerts JSON format. Keys of an
+// alert that are not used by the mapping are kept, so they are read as alert
+// fields or labels as usual.
+func (mapping *FieldMapping) Apply(data []byte) ([]byte, error) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	found, ok := lookupPath(document, mapping.root)
+	items, isArray := found.([]interface{})
+	if !ok || !isArray {
+		return nil, fmt.Errorf("no array of alerts at '%s'", mapping.Alerts)
+	}
+
+	alerts := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		source, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] is not an object", mapping.Alerts, i)
+		}
+		alert, err := mapping.mapAlert(source)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %v", mapping.Alerts, i, err)
+		}
+		alerts[i] = alert
+	}
+
+	return json.Marshal(map[string]interface{}{"alerts": alerts})
+}
+
+func (mapping *FieldMapping) mapAlert(source map[string]interface{}) (map[string]interface{}, error) {
+	alert := make(map[string]interface{}, len(source))
+	for key, value := range source {
+		alert[key] = value
+	}
+	for _, path := range mapping.paths {
+		if path[0].index < 0 {
+			deletePath(alert, path) // Used by the mapping, not an extra key
+		}
+	}
+
+	labels := make(map[string]interface{})
+	annotations := make(map[string]interface{})
+	for field, path := range mapping.paths {
+		value, ok := lookupPath(source, path)
+		if !ok || value == nil {
+			continue
+		}
+
+		converted, err := mapping.convert(field, value)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %v", field, err)
+		}
+		if _, name, isLabel := labelField(Alert{}, field); isLabel {
+			target := labels
+			if strings.HasPrefix(strings.ToLower(field), annotationsPrefix) {
+				target = annotations
+			}
+			target[name] = converted
+			continue
+		}
+		alert[field] = converted
+	}
+
+	// Fields under their own name still need coercion, e.g. epoch timestamps
+	for _, field := range []string{"timestamp", "severity", "value", "threshold"} {
+		if _, mapped := mapping.paths[field]; mapped || alert[field] == nil {
+			continue
+		}
+		converted, err := mapping.convert(field, alert[field])
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %v", field, err)
+		}
+		alert[field] = converted
+	}
+
+	// Mapped labels go into explicit objects, so they win over extra keys
+	for name, target := range map[string]map[string]interface{}{"labels": labels, "annotations": annotations} {
+		if len(target) == 0 {
+			continue
+		}
+		if existing, ok := alert[name].(map[string]interface{}); ok {
+			for key, value := range existing {
+				if _, set := target[key]; !set {
+					target[key] = value
+				}
+			}
+		}
+		alert[name] = target
+	}
+	return alert, nil
+}
+
+// deletePath removes the value at a path from an object, keeping its
+// siblings. Objects on the way are copied, as they are shared with the
+// source, and dropped once empty. A path through an array removes the whole
+// array, as removing one item would shift the others.
+func deletePath(object map[string]interface{}, path []pathSegment) {
+	key := path[0].key
+	if len(path) == 1 || path[1].index >= 0 {
+		delete(object, key)
+		return
+	}
+	child, ok := object[key].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	copied := make(map[string]interface{}, len(child))
+	for name, value := range child {
+		copied[name] = value
+	}
+	deletePath(copied, path[1:])
+	if len(copied) == 0 {
+		delete(object, key)
+	} else {
+		object[key] = copied
+	}
+}
+
+// convert coerces a source value to the type of an alert field
+func (mapping *FieldMapping) convert(field string, value interface{}) (interface{}, error) {
+	switch field {
+	case "timestamp":
+		t, err := mapping.parseTimestamp(value)
+		if err != nil {
+			return nil, err
+		}
+		return t.Format(time.RFC3339Nano), nil
+	case "value", "threshold":
+		return toFloat(value)
+	case "severity":
+		severity := toString(value)
+		if mapped, ok := mapping.Severities[severity]; ok {
+			return mapped, nil
+		}
+		for from, to := range mapping.Severities {
+			if strings.EqualFold(from, severity) {
+				return to, nil
+			}
+		}
+		return severity, nil
+	}
+	return toString(value), nil
+}
+
+// parseTimestamp reads a timestamp in the mapping's format, or in any format
+// parseTimestamp accepts if none is set
+func (mapping *FieldMapping) parseTimestamp(value interface{}) (time.Time, error) {
+	switch format := mapping.TimestampFormat; format {
+	case "":
+		return parseTimestamp(value)
+	case timestampEpochS, timestampEpochMs:
+		epoch, err := toFloat(value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("'%v' is not an epoch timestamp", value)
+		}
+		if format == timestampEpochMs {
+			return time.UnixMilli(int64(epoch)).UTC(), nil
+		}
+		sec, frac := math.Modf(epoch)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("'%v' is not a timestamp", value)
+	}
+	layout := mapping.TimestampFormat
+	if layout == timestampRFC3339 {
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' does not match the timestamp format '%s'", text, layout)
+	}
+	return t, nil
+}
+
+// toFloat accepts numbers and numeric strings
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a number", v)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("'%v' is not a number", value)
+}
+
+// toString formats numbers without a trailing .0, so IDs like 1234 read well
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+const foreignAlertsJSON = `{
+	"data": {"items": [
+		{"uuid": 1234, "ts": 1745323200000, "sev": "P1", "svc": "payments",
+		 "metric": {"name": "latency", "value": "950.5"}, "limit": 500,
+		 "meta": {"region": "eu-west-1"}, "runbook_url": "https://runbooks/payments"},
+		{"uuid": "A-2", "ts": "1745323260", "sev": "p3", "svc": "search",
+		 "metric": {"name": "errors", "value": 3}, "limit": "1", "cluster": "prod-a"}
+	]}
+}`
+
+const foreignMappingJSON = `{
+	"alerts": "$.data.items",
+	"fields": {
+		"id": "uuid",
+		"timestamp": "ts",
+		"severity": "sev",
+		"service": "svc",
+		"metric": "metric.name",
+		"value": "metric.value",
+		"threshold": "limit",
+		"labels.region": "meta.region"
+	},
+	"severities": {"P1": "critical", "P2": "warning", "P3": "info"}
+}`
+
+func TestLoadAlertsWithMapping(t *testing.T) {
+	mappingFile := createTestFile(t, foreignMappingJSON)
+	defer os.Remove(mappingFile)
+	alertsFile := createTestFile(t, foreignAlertsJSON)
+	defer os.Remove(alertsFile)
+
+	mapping, err := loadFieldMapping(mappingFile, defaultSeverities)
+	if err != nil {
+		t.Fatalf("Unexpected error loading mapping: %v", err)
+	}
+
+	alerts, err := loadAlertsFromFile(alertsFile, LoadOptions{Mapping: mapping})
+	if err != nil {
+		t.Fatalf("Unexpected error loading alerts: %v", err)
+	}
+	if len(alerts.Alerts) != 2 {
+		t.Fatalf("Expected 2 alerts, got %d", len(alerts.Alerts))
+	}
+
+	first := alerts.Alerts[0]
+	if first.ID != "1234" || first.Service != "payments" || first.Severity != "critical" || first.Metric != "latency" {
+		t.Errorf("Expected mapped string fields, got %+v", first)
+	}
+	if first.Value != 950.5 || first.Threshold != 500 {
+		t.Errorf("Expected numeric strings to be coerced, got value %v and threshold %v", first.Value, first.Threshold)
+	}
+	if expected := time.Date(2025, 4, 22, 12, 0, 0, 0, time.UTC); !first.Timestamp.Equal(expected) {
+		t.Errorf("Expected epoch milliseconds to be read as %v, got %v", expected, first.Timestamp)
+	}
+	if first.Labels["region"] != "eu-west-1" || first.Annotations["runbook_url"] != "https://runbooks/payments" {
+		t.Errorf("Expected the mapped label and the extra annotation, got %v and %v", first.Labels, first.Annotations)
+	}
+	for _, key := range []string{"uuid", "svc", "sev", "ts", "meta"} {
+		if _, ok := first.Labels[key]; ok {
+			t.Errorf("Expected source key %s used by the mapping not to become a label", key)
+		}
+	}
+
+	second := alerts.Alerts[1]
+	if second.Severity != "info" || second.Threshold != 1 || second.Labels["cluster"] != "prod-a" {
+		t.Errorf("Expected case-insensitive severity mapping and extra labels, got %+v", second)
+	}
+	if expected := time.Date(2025, 4, 22, 12, 1, 0, 0, time.UTC); !second.Timestamp.Equal(expected) {
+		t.Errorf("Expected epoch seconds to be read as %v, got %v", expected, second.Timestamp)
+	}
+}
+
+func TestMappingWithoutFields(t *testing.T) {
+	// Only coercion and severities: the fields keep their own names
+	mapping := &FieldMapping{Alerts: "$", TimestampFormat: "2006-01-02 15:04", Severities: map[string]string{"high": "critical"}}
+	if err := mapping.compile(defaultSeverities); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := mapping.Apply([]byte(`[{"id": "A", "timestamp": "2025-04-22 12:30", "severity": "high", "value": "7"}]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testFile := createTestFile(t, string(data))
+	defer os.Remove(testFile)
+
+	alerts, err := loadAlertsFromFile(testFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	alert := alerts.Alerts[0]
+	if alert.Severity != "critical" || alert.Value != 7 || alert.Timestamp.Minute() != 30 {
+		t.Errorf("Expected coerced fields, got %+v", alert)
+	}
+}
+
+func TestMappingKeepsSiblingKeys(t *testing.T) {
+	mapping := &FieldMapping{Alerts: "$", Fields: map[string]string{
+		"id":       "id",
+		"severity": "labels.severity",
+		"service":  "details.service",
+	}}
+	if err := mapping.compile(defaultSeverities); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := mapping.Apply([]byte(`[{"id": "A", "labels": {"severity": "critical", "region": "eu", "team": "web"},
+		"details": {"service": "checkout"}}]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var alerts Alerts
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	alert := alerts.Alerts[0]
+	if alert.Severity != "critical" || alert.Service != "checkout" {
+		t.Errorf("Expected the mapped fields, got %+v", alert)
+	}
+	if len(alert.Labels) != 2 || alert.Labels["region"] != "eu" || alert.Labels["team"] != "web" {
+		t.Errorf("Expected only the mapped label to be removed, got %v", alert.Labels)
+	}
+}
+
+func TestMappingErrors(t *testing.T) {
+	invalid := []FieldMapping{
+		{Fields: map[string]string{"priority": "p"}},
+		{Fields: map[string]string{"id": "items[x]"}},
+		{Fields: map[string]string{"id": "$"}},
+		{Severities: map[string]string{"P1": "urgent"}},
+	}
+	for _, mapping := range invalid {
+		if err := mapping.compile(defaultSeverities); err == nil {
+			t.Errorf("Expected an error for mapping %+v", mapping)
+		}
+	}
+
+	mapping := &FieldMapping{Fields: map[string]string{"value": "v"}}
+	if err := mapping.compile(defaultSeverities); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := mapping.Apply([]byte(`{"alerts": [{"v": "high"}]}`)); err == nil {
+		t.Error("Expected an error for a value that is not a number")
+	}
+	if _, err := mapping.Apply([]byte(`{"items": []}`)); err == nil {
+		t.Error("Expected an error when there is no array of alerts")
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	path, err := parsePath("$.data.items[2][0].name")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []pathSegment{{key: "data", index: -1}, {key: "items", index: -1}, {index: 2}, {index: 0}, {key: "name", index: -1}}
+	if len(path) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, path)
+	}
+	for i := range expected {
+		if path[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, path)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const defaultNotifyTemplate = `{{.Title}}
+{{range .Alerts}}• [{{printf "%.2f" .Priority}}] {{.Severity}} {{.ID}} {{.Service}}/{{.Component}}: {{.Description}}
+{{end}}`
+
+// NotifierConfig holds where and how notify sends alerts
+type NotifierConfig struct {
+	Type     string        // slack or webhook
+	Webhook  string        // URL to post to
+	Template string        // text/template file for the message text
+	State    string        // File recording the alerts already sent
+	Retries  int           // Retries of failed requests
+	Backoff  time.Duration // Initial retry delay, doubled after each retry
+}
+
+// DefaultNotifierConfig returns the default notifier settings
+func DefaultNotifierConfig() NotifierConfig {
+	return NotifierConfig{Type: "slack", Retries: 3, Backoff: time.Second}
+}
+
+// NotificationBatch is a group of alerts sent in a single message. It is
+// also the data passed to message templates.
+type NotificationBatch struct {
+	Group  string
+	Title  string
+	Alerts []Alert
+}
+
+// NotifyState records which alerts have already been notified, so later
+// runs can send only new ones
+type NotifyState struct {
+	LastRun  time.Time `json:"last_run"`
+	Notified []string  `json:"notified"`
+}
+
+// slackPayload is the body of a Slack incoming webhook request
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// webhookPayload is the body of a generic JSON webhook request
+type webhookPayload struct {
+	Group  string  `json:"group,omitempty"`
+	Text   string  `json:"text"`
+	Alerts []Alert `json:"alerts"`
+}
+
+// HTTPRequestRecord is a request written to the dry-run sink instead of being sent
+type HTTPRequestRecord struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// webhookSender posts JSON payloads, retrying failed requests with
+// exponential backoff. With a dry-run sink set, requests are written there
+// instead of being sent.
+type webhookSender struct {
+	client  *http.Client
+	retries int
+	backoff time.Duration
+	dryRun  io.Writer
+	sleep   func(time.Duration)
+}
+
+func newWebhookSender(retries int, backoff time.Duration, dryRun io.Writer) *webhookSender {
+	return &webhookSender{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		retries: retries,
+		backoff: backoff,
+		dryRun:  dryRun,
+		sleep:   time.Sleep,
+	}
+}
+
+func (s *webhookSender) send(url string, body []byte, headers map[string]string) error {
+	headers = withHeader(headers, "Content-Type", "application/json")
+
+	if s.dryRun != nil {
+		// Keep credentials out of files on disk
+		recorded := make(map[string]string, len(headers))
+		for key, value := range headers {
+			if strings.EqualFold(key, "Authorization") {
+				value = "REDACTED"
+			}
+			recorded[key] = value
+		}
+		record, err := json.Marshal(HTTPRequestRecord{Method: http.MethodPost, URL: redactURL(url), Headers: recorded, Body: body})
+		if err != nil {
+			return fmt.Errorf("error encoding dry-run record: %v", err)
+		}
+		_, err = fmt.Fprintf(s.dryRun, "%s\n", record)
+		return err
+	}
+
+	var lastErr error
+	delay := s.backoff
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		if attempt > 0 {
+			s.sleep(delay)
+			delay *= 2
+		}
+
+		retry, err := s.post(url, body, headers)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return fmt.Errorf("error sending to '%s': %v", url, lastErr)
+}
+
+// redactURL keeps only the scheme and host of a URL. The path and query of
+// webhook URLs, such as Slack's, are the credential.
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "REDACTED"
+	}
+	redacted := &url.URL{Scheme: parsed.Scheme, Host: parsed.Host}
+	if strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" {
+		redacted.Path = "/REDACTED"
+	}
+	return redacted.String()
+}
+
+// withHeader returns a copy of headers with the given header set
+func withHeader(headers map[string]string, key, value string) map[string]string {
+	merged := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		merged[k] = v
+	}
+	merged[key] = value
+	return merged
+}
+
+// post sends a single request and reports whether a failure is worth retrying
+func (s *webhookSender) post(url string, body []byte, headers map[string]string) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// Rate limiting and server errors are transient, other client errors are not
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+// buildNotificationBatches splits alerts into one batch per group, ordered by
+// group name. Without a group field everything goes into a single batch.
+func buildNotificationBatches(alerts Alerts, groupBy string) []NotificationBatch {
+	if groupBy == "" {
+		return []NotificationBatch{{
+			Title:  fmt.Sprintf("🔥 %d high priority alerts", len(alerts.Alerts)),
+			Alerts: alerts.Alerts,
+		}}
+	}
+
+	grouped := alerts.Group(groupBy)
+	keys := make([]string, 0, len(grouped))
+	for key := range grouped {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	batches := make([]NotificationBatch, 0, len(keys))
+	for _, key := range keys {
+		group := grouped[key]
+		batches = append(batches, NotificationBatch{
+			Group:  key,
+			Title:  fmt.Sprintf("🔥 %d high priority alerts for %s %s", len(group.Alerts), groupBy, key),
+			Alerts: group.Alerts,
+		})
+	}
+	return batches
+}
+
+// buildNotificationPayload renders a batch into a Slack or generic webhook body
+func buildNotificationPayload(kind string, tmpl *template.Template, batch NotificationBatch) ([]byte, error) {
+	var text strings.Builder
+	if err := tmpl.Execute(&text, batch); err != nil {
+		return nil, fmt.Errorf("error rendering message template: %v", err)
+	}
+
+	switch kind {
+	case "slack":
+		return json.Marshal(slackPayload{Text: text.String()})
+	case "webhook":
+		return json.Marshal(webhookPayload{Group: batch.Group, Text: text.String(), Alerts: batch.Alerts})
+	default:
+		return nil, fmt.Errorf("invalid notifier type '%s'. Valid types: slack, webhook", kind)
+	}
+}
+
+func loadNotifyState(filename string) (*NotifyState, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return &NotifyState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading state file '%s': %v", filename, err)
+	}
+
+	var state NotifyState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error parsing state file '%s': %v", filename, err)
+	}
+	return &state, nil
+}
+
+func (state *NotifyState) save(filename string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0o600)
+}
+
+// filterNew drops alerts that were notified in an earlier run
+func (state *NotifyState) filterNew(alerts Alerts) Alerts {
+	notified := make(map[string]bool, len(state.Notified))
+	for _, id := range state.Notified {
+		notified[id] = true
+	}
+
+	var fresh []Alert
+	for _, alert := range alerts.Alerts {
+		if !notified[alert.ID] {
+			fresh = append(fresh, alert)
+		}
+	}
+	return Alerts{Alerts: fresh}
+}
+
+func (state *NotifyState) record(alerts []Alert) {
+	seen := make(map[string]bool, len(state.Notified))
+	for _, id := range state.Notified {
+		seen[id] = true
+	}
+	for _, alert := range alerts {
+		if !seen[alert.ID] {
+			seen[alert.ID] = true
+			state.Notified = append(state.Notified, alert.ID)
+		}
+	}
+	state.LastRun = time.Now().UTC()
+}
+
+func runNotifyCommand(args []string) error {
+	config := newConfig()
+	var groupBy, dryRunFile string
+	var top int
+	fs := newCommandFlagSet("notify", "-i <input-file> --webhook <url> [OPTIONS]",
+		"Sends the highest priority alerts to a Slack incoming webhook or a generic JSON webhook.")
+	registerInputFlags(fs, config)
+	registerAnalysisFlags(fs, config)
+	registerNotifierFlags(fs, config)
+	fs.IntVar(&top, "top", 10, "Number of highest priority alerts to send (0 sends all)")
+	fs.StringVar(&groupBy, "groupby", "", "Send one message per group of this field")
+	fs.StringVar(&dryRunFile, "dry-run", "", "Write the HTTP requests to this file instead of sending them")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	notifier := config.notifier
+	if notifier.Webhook == "" {
+		return fmt.Errorf("webhook URL is required. Use --webhook to specify it")
+	}
+	if notifier.Type != "slack" && notifier.Type != "webhook" {
+		return fmt.Errorf("invalid notifier type '%s'. Valid types: slack, webhook", notifier.Type)
+	}
+	if top < 0 || notifier.Retries < 0 {
+		return fmt.Errorf("top and retries must be positive numbers")
+	}
+	if groupBy != "" && !isGroupableField(groupBy) {
+		return fmt.Errorf("invalid groupby field '%s'. Valid fields: %s", groupBy, groupableFieldsHelp())
+	}
+
+	tmplText := defaultNotifyTemplate
+	if notifier.Template != "" {
+		data, err := os.ReadFile(notifier.Template)
+		if err != nil {
+			return fmt.Errorf("error reading template file '%s': %v", notifier.Template, err)
+		}
+		tmplText = string(data)
+	}
+	tmpl, err := template.New("message").Parse(tmplText)
+	if err != nil {
+		return fmt.Errorf("error parsing message template: %v", err)
+	}
+
+	alerts, err := loadPreparedAlerts(config)
+	if err != nil {
+		return err
+	}
+
+	state := &NotifyState{}
+	if notifier.State != "" {
+		if state, err = loadNotifyState(notifier.State); err != nil {
+			return err
+		}
+		fresh := state.filterNew(*alerts)
+		alerts = &fresh
+	}
+	if top > 0 && len(alerts.Alerts) > top {
+		alerts.Alerts = alerts.Alerts[:top]
+	}
+	if len(alerts.Alerts) == 0 {
+		fmt.Println("✅ No new alerts to send")
+		return nil
+	}
+
+	var sink io.Writer
+	if dryRunFile != "" {
+		file, err := os.Create(dryRunFile)
+		if err != nil {
+			return fmt.Errorf("error creating dry-run file '%s': %v", dryRunFile, err)
+		}
+		defer file.Close()
+		sink = file
+	}
+	sender := newWebhookSender(notifier.Retries, notifier.Backoff, sink)
+
+	// Record each batch as soon as it is sent, so a rerun after a failed
+	// batch doesn't send the earlier ones again
+	batches := buildNotificationBatches(*alerts, groupBy)
+	for i, batch := range batches {
+		body, err := buildNotificationPayload(notifier.Type, tmpl, batch)
+		if err != nil {
+			return err
+		}
+		if err := sender.send(notifier.Webhook, body, nil); err != nil {
+			return fmt.Errorf("%v (%d of %d messages were sent)", err, i, len(batches))
+		}
+		if notifier.State != "" && dryRunFile == "" {
+			state.record(batch.Alerts)
+			if err := state.save(notifier.State); err != nil {
+				return fmt.Errorf("error writing state file '%s': %v", notifier.State, err)
+			}
+		}
+	}
+
+	if dryRunFile != "" {
+		fmt.Printf("📝 Wrote %d requests for %d alerts to %s\n", len(batches), len(alerts.Alerts), dryRunFile)
+		return nil
+	}
+
+	fmt.Printf("📨 Sent %d alerts in %d messages to %s\n", len(alerts.Alerts), len(batches), notifier.Type)
+	return nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestWebhookSenderRetries(t *testing.T) {
+	var requests int
+	var lastBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		lastBody, _ = io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected JSON content type, got %s", r.Header.Get("Content-Type"))
+		}
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	sender := newWebhookSender(3, time.Second, nil)
+	sender.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	if err := sender.send(server.URL, []byte(`{"text":"hi"}`), nil); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+	if string(lastBody) != `{"text":"hi"}` {
+		t.Errorf("Unexpected body: %s", lastBody)
+	}
+	if len(delays) != 2 || delays[0] != time.Second || delays[1] != 2*time.Second {
+		t.Errorf("Expected exponential backoff of 1s, 2s, got %v", delays)
+	}
+}
+
+func TestWebhookSenderDoesNotRetryClientErrors(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sender := newWebhookSender(3, time.Millisecond, nil)
+	sender.sleep = func(time.Duration) {}
+
+	err := sender.send(server.URL, []byte(`{}`), nil)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected error about status 400, got: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
+
+func TestWebhookSenderDryRun(t *testing.T) {
+	var sink bytes.Buffer
+	sender := newWebhookSender(3, time.Second, &sink)
+
+	webhook := "https://hooks.slack.com/services/T000/B000/secret?token=secret"
+	if err := sender.send(webhook, []byte(`{"text":"hi"}`), nil); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var record HTTPRequestRecord
+	if err := json.Unmarshal(sink.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a JSON record, got: %s", sink.String())
+	}
+	if string(record.Body) != `{"text":"hi"}` {
+		t.Errorf("Unexpected dry-run record: %+v", record)
+	}
+	// The path and query of a Slack webhook are its secret
+	if record.URL != "https://hooks.slack.com/REDACTED" || strings.Contains(sink.String(), "secret") {
+		t.Errorf("Expected the webhook path and query to be redacted, got %s", record.URL)
+	}
+}
+
+func TestBuildNotificationBatches(t *testing.T) {
+	alerts := createTestAlerts()
+
+	single := buildNotificationBatches(alerts, "")
+	if len(single) != 1 || len(single[0].Alerts) != 4 {
+		t.Fatalf("Expected a single batch with 4 alerts, got %+v", single)
+	}
+
+	bySeverity := buildNotificationBatches(alerts, "severity")
+	if len(bySeverity) != 3 {
+		t.Fatalf("Expected 3 batches, got %d", len(bySeverity))
+	}
+	if bySeverity[0].Group != "critical" || len(bySeverity[0].Alerts) != 2 {
+		t.Errorf("Expected first batch to hold the 2 critical alerts, got %s with %d",
+			bySeverity[0].Group, len(bySeverity[0].Alerts))
+	}
+}
+
+func TestBuildNotificationPayload(t *testing.T) {
+	tmpl := template.Must(template.New("message").Parse(defaultNotifyTemplate))
+	batch := NotificationBatch{Group: "critical", Title: "Title", Alerts: createTestAlerts().Alerts[:1]}
+
+	body, err := buildNotificationPayload("slack", tmpl, batch)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	var slack slackPayload
+	if err := json.Unmarshal(body, &slack); err != nil {
+		t.Fatalf("Expected valid JSON, got: %s", body)
+	}
+	if !strings.HasPrefix(slack.Text, "Title\n") || !strings.Contains(slack.Text, "ALT-001") {
+		t.Errorf("Unexpected Slack text: %q", slack.Text)
+	}
+
+	body, err = buildNotificationPayload("webhook", tmpl, batch)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	var webhook webhookPayload
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		t.Fatalf("Expected valid JSON, got: %s", body)
+	}
+	if webhook.Group != "critical" || len(webhook.Alerts) != 1 || webhook.Alerts[0].ID != "ALT-001" {
+		t.Errorf("Unexpected webhook payload: %+v", webhook)
+	}
+
+	if _, err := buildNotificationPayload("email", tmpl, batch); err == nil {
+		t.Error("Expected error for invalid notifier type, got nil")
+	}
+}
+
+func TestNotifyState(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := loadNotifyState(stateFile)
+	if err != nil {
+		t.Fatalf("Expected missing state file to be empty state, got: %v", err)
+	}
+
+	alerts := createTestAlerts()
+	state.record(alerts.Alerts[:2])
+	if err := state.save(stateFile); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	reloaded, err := loadNotifyState(stateFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	fresh := reloaded.filterNew(alerts)
+	if len(fresh.Alerts) != 2 || fresh.Alerts[0].ID != "ALT-003" {
+		t.Errorf("Expected only ALT-003 and ALT-004 to be new, got %+v", fresh.Alerts)
+	}
+}
+
+func TestRunNotifyCommand_DryRun(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	dryRunFile := filepath.Join(t.TempDir(), "payloads.jsonl")
+
+	err := runNotifyCommand([]string{"-i", testFile, "--webhook", "http://localhost:9/hook",
+		"--groupby", "severity", "--dry-run", dryRunFile})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(dryRunFile)
+	if err != nil {
+		t.Fatalf("Expected dry-run file, got: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("Expected 2 requests (one per severity), got %d", lines)
+	}
+}
+
+func TestRunNotifyCommand_StateAfterFailedBatch(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	// The first message (critical) goes through, the second one fails
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	args := []string{"-i", testFile, "--webhook", server.URL, "--groupby", "severity", "--state", stateFile, "--retries", "0"}
+	if err := runNotifyCommand(args); err == nil || !strings.Contains(err.Error(), "1 of 2 messages were sent") {
+		t.Fatalf("Expected the second message to fail, got: %v", err)
+	}
+
+	state, err := loadNotifyState(stateFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	alerts, _ := loadAlertsFromFile(testFile)
+	if fresh := state.filterNew(*alerts); len(fresh.Alerts) != len(alerts.Alerts)-1 {
+		t.Errorf("Expected the alert of the sent message to be recorded, got %d new of %d", len(fresh.Alerts), len(alerts.Alerts))
+	}
+}
+
+func TestRunNotifyCommand_Settings(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	settingsFile := createTestFile(t, "notifier: webhook\nwebhook-url: http://localhost:9/hook\n")
+	defer os.Remove(settingsFile)
+	dryRunFile := filepath.Join(t.TempDir(), "payloads.jsonl")
+
+	err := runNotifyCommand([]string{"--config", settingsFile, "-i", testFile, "--dry-run", dryRunFile})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(dryRunFile)
+	if err != nil {
+		t.Fatalf("Expected dry-run file, got: %v", err)
+	}
+	var record HTTPRequestRecord
+	var payload webhookPayload
+	if err := json.Unmarshal(data, &record); err != nil || json.Unmarshal(record.Body, &payload) != nil {
+		t.Fatalf("Expected a single request, got: %s", data)
+	}
+	if len(payload.Alerts) != 2 {
+		t.Errorf("Expected a generic webhook payload with 2 alerts, got: %s", record.Body)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OnCallSchedules is the top level of a schedules file
+type OnCallSchedules struct {
+	Schedules []OnCallSchedule `json:"schedules"`
+}
+
+// OnCallSchedule is a team's on-call rotation with optional overrides.
+// Times without an offset are in the schedule's timezone.
+type OnCallSchedule struct {
+	Team      string           `json:"team"`
+	Timezone  string           `json:"timezone,omitempty"`
+	Rotation  OnCallRotation   `json:"rotation"`
+	Overrides []OnCallOverride `json:"overrides,omitempty"`
+
+	location *time.Location
+	start    time.Time
+}
+
+// OnCallRotation hands over between members at a fixed interval
+type OnCallRotation struct {
+	Start   string   `json:"start"`   // First handoff, e.g. "2024-04-22T09:00:00"
+	Handoff string   `json:"handoff"` // "daily", "weekly" or a duration such as "12h"
+	Members []string `json:"members"`
+
+	days   int           // Calendar days per shift for daily and weekly rotations
+	length time.Duration // Shift length for duration-based rotations
+}
+
+// OnCallOverride puts someone else on call for a period
+type OnCallOverride struct {
+	User  string `json:"user"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	start, end time.Time
+}
+
+// OnCallLoad is the alert load of one on-call engineer
+type OnCallLoad struct {
+	Engineer      string
+	Alerts        int
+	Critical      int
+	TotalPriority float64
+}
+
+func loadOnCallSchedules(filename string) (map[string]*OnCallSchedule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading schedules file '%s': %v", filename, err)
+	}
+
+	var schedules OnCallSchedules
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("error parsing schedules from '%s': %v", filename, err)
+	}
+
+	byTeam := make(map[string]*OnCallSchedule, len(schedules.Schedules))
+	for i := range schedules.Schedules {
+		schedule := &schedules.Schedules[i]
+		if err := schedule.compile(); err != nil {
+			return nil, fmt.Errorf("invalid schedule for team '%s' in '%s': %v", schedule.Team, filename, err)
+		}
+		if _, exists := byTeam[schedule.Team]; exists {
+			return nil, fmt.Errorf("duplicate schedule for team '%s' in '%s'", schedule.Team, filename)
+		}
+		byTeam[schedule.Team] = schedule
+	}
+
+	return byTeam, nil
+}
+
+// compile validates the schedule and parses its times
+func (s *OnCallSchedule) compile() error {
+	if s.Team == "" {
+		return fmt.Errorf("schedule needs a team")
+	}
+	if len(s.Rotation.Members) == 0 {
+		return fmt.Errorf("rotation has no members")
+	}
+
+	s.location = time.UTC
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return fmt.Errorf("unknown timezone '%s'", s.Timezone)
+		}
+		s.location = loc
+	}
+
+	var err error
+	if s.start, err = parseScheduleTime(s.Rotation.Start, s.location); err != nil {
+		return fmt.Errorf("invalid rotation start: %v", err)
+	}
+
+	switch s.Rotation.Handoff {
+	case "daily":
+		s.Rotation.days = 1
+	case "weekly":
+		s.Rotation.days = 7
+	default:
+		length, err := time.ParseDuration(s.Rotation.Handoff)
+		if err != nil || length <= 0 {
+			return fmt.Errorf("invalid handoff '%s'. Use daily, weekly or a duration", s.Rotation.Handoff)
+		}
+		s.Rotation.length = length
+	}
+
+	for i := range s.Overrides {
+		override := &s.Overrides[i]
+		if override.start, err = parseScheduleTime(override.Start, s.location); err != nil {
+			return fmt.Errorf("invalid override start: %v", err)
+		}
+		if override.end, err = parseScheduleTime(override.End, s.location); err != nil {
+			return fmt.Errorf("invalid override end: %v", err)
+		}
+		if !override.end.After(override.start) {
+			return fmt.Errorf("override for '%s' ends before it starts", override.User)
+		}
+	}
+
+	return nil
+}
+
+// parseScheduleTime parses RFC3339, or a local time in the given location
+func parseScheduleTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is not a valid time", s)
+	}
+	return t, nil
+}
+
+// OnCallAt returns who was on call at the given time, or "" before the
+// rotation started
+func (s *OnCallSchedule) OnCallAt(t time.Time) string {
+	for _, override := range s.Overrides {
+		if !t.Before(override.start) && t.Before(override.end) {
+			return override.User
+		}
+	}
+
+	if t.Before(s.start) {
+		return ""
+	}
+
+	shift := s.shiftIndex(t)
+	return s.Rotation.Members[shift%len(s.Rotation.Members)]
+}
+
+// shiftIndex returns the number of handoffs between the rotation start and t.
+// Daily and weekly rotations hand off at the same wall-clock time across DST.
+func (s *OnCallSchedule) shiftIndex(t time.Time) int {
+	if s.Rotation.length > 0 {
+		return int(t.Sub(s.start) / s.Rotation.length)
+	}
+
+	days := s.Rotation.days
+	shift := int(t.Sub(s.start) / (time.Duration(days) * 24 * time.Hour))
+	start := s.start.In(s.location)
+	for start.AddDate(0, 0, (shift+1)*days).Compare(t) <= 0 {
+		shift++
+	}
+	for shift > 0 && start.AddDate(0, 0, shift*days).After(t) {
+		shift--
+	}
+	return shift
+}
+
+// AnnotateOnCall sets the routed team of every alert and, when the team has
+// a schedule, who was on call at the alert's timestamp. Priorities must be
+// calculated first, as routes can match on them.
+func (alerts *Alerts) AnnotateOnCall(root *Route, schedules map[string]*OnCallSchedule) {
+	for i := range alerts.Alerts {
+		alert := &alerts.Alerts[i]
+		alert.Team = root.Receivers(*alert)[0]
+		if schedule, ok := schedules[alert.Team]; ok {
+			alert.OnCall = schedule.OnCallAt(alert.Timestamp)
+		}
+	}
+}
+
+// onCallLoads summarizes the alert load per on-call engineer, highest load first
+func (alerts Alerts) onCallLoads() []OnCallLoad {
+	loads := make(map[string]*OnCallLoad)
+	for _, alert := range alerts.Alerts {
+		if alert.OnCall == "" {
+			continue
+		}
+		load, exists := loads[alert.OnCall]
+		if !exists {
+			load = &OnCallLoad{Engineer: alert.OnCall}
+			loads[alert.OnCall] = load
+		}
+		load.Alerts++
+		load.TotalPriority += alert.Priority
+		if strings.ToLower(alert.Severity) == "critical" {
+			load.Critical++
+		}
+	}
+
+	result := make([]OnCallLoad, 0, len(loads))
+	for _, load := range loads {
+		result = append(result, *load)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalPriority != result[j].TotalPriority {
+			return result[i].TotalPriority > result[j].TotalPriority
+		}
+		return result[i].Engineer < result[j].Engineer
+	})
+	return result
+}
+
+func showOnCallLoad(w io.Writer, alerts *Alerts) {
+	loads := alerts.onCallLoads()
+	if len(loads) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n👤 Load per On-Call Engineer:\n")
+	for _, load := range loads {
+		fmt.Fprintf(w, "  %s: %d alerts (%d critical), total priority %.2f\n",
+			load.Engineer, load.Alerts, load.Critical, load.TotalPriority)
+	}
+}
+
+// onCallOrUnknown renders an on-call engineer for display
+func onCallOrUnknown(engineer string) string {
+	if engineer == "" {
+		return "unknown"
+	}
+	return engineer
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func createTestSchedule(t *testing.T, handoff string) *OnCallSchedule {
+	schedule := &OnCallSchedule{
+		Team:     "payments-team",
+		Timezone: "Europe/Helsinki",
+		Rotation: OnCallRotation{
+			Start:   "2024-03-25T09:00:00",
+			Handoff: handoff,
+			Members: []string{"alice", "bob", "carol"},
+		},
+		Overrides: []OnCallOverride{
+			{User: "dave", Start: "2024-04-10T00:00:00", End: "2024-04-11T00:00:00"},
+		},
+	}
+	if err := schedule.compile(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	return schedule
+}
+
+func TestOnCallAt(t *testing.T) {
+	weekly := createTestSchedule(t, "weekly")
+
+	tests := []struct {
+		time     string
+		expected string
+	}{
+		{"2024-03-25T06:59:59Z", ""},      // Before the rotation started (09:00 EET)
+		{"2024-03-25T07:00:00Z", "alice"}, // First shift
+		{"2024-04-01T05:59:59Z", "alice"}, // 08:59:59 EEST, after the DST change
+		{"2024-04-01T06:00:00Z", "bob"},   // 09:00 EEST handoff
+		{"2024-04-10T12:00:00Z", "dave"},  // Override
+		{"2024-04-15T06:00:00Z", "alice"}, // Rotation wraps around
+	}
+
+	for _, test := range tests {
+		if got := weekly.OnCallAt(mustParseTime(t, test.time)); got != test.expected {
+			t.Errorf("OnCallAt(%s) = %q, expected %q", test.time, got, test.expected)
+		}
+	}
+
+	twelveHours := createTestSchedule(t, "12h")
+	if got := twelveHours.OnCallAt(mustParseTime(t, "2024-03-25T19:30:00Z")); got != "bob" {
+		t.Errorf("Expected bob on the second 12h shift, got %q", got)
+	}
+}
+
+func TestAnnotateOnCall(t *testing.T) {
+	alerts := createTestAlerts()
+	alerts.CalculateAllPriorities()
+	for i := range alerts.Alerts {
+		alerts.Alerts[i].Timestamp = mustParseTime(t, "2024-03-26T12:00:00Z")
+	}
+
+	schedules := map[string]*OnCallSchedule{"payments-team": createTestSchedule(t, "weekly")}
+	alerts.AnnotateOnCall(createTestRoutes(), schedules)
+
+	if alerts.Alerts[1].Team != "payments-team" || alerts.Alerts[1].OnCall != "alice" {
+		t.Errorf("Expected payments-team/alice, got %s/%s", alerts.Alerts[1].Team, alerts.Alerts[1].OnCall)
+	}
+	if alerts.Alerts[2].Team != "auth-team" || alerts.Alerts[2].OnCall != "" {
+		t.Errorf("Expected auth-team without schedule, got %s/%s", alerts.Alerts[2].Team, alerts.Alerts[2].OnCall)
+	}
+
+	loads := alerts.onCallLoads()
+	if len(loads) != 1 || loads[0].Engineer != "alice" || loads[0].Alerts != 1 {
+		t.Errorf("Expected alice to carry the single scheduled alert, got %+v", loads)
+	}
+}
+
+func TestLoadOnCallSchedules(t *testing.T) {
+	valid := `{"schedules": [
+  {"team": "payments-team", "timezone": "UTC", "rotation": {"start": "2024-04-22T09:00:00", "handoff": "daily", "members": ["alice", "bob"]}}
+]}`
+	testFile := createTestFile(t, valid)
+	defer os.Remove(testFile)
+
+	schedules, err := loadOnCallSchedules(testFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if schedules["payments-team"] == nil {
+		t.Fatal("Expected a schedule for payments-team")
+	}
+
+	invalid := map[string]string{
+		`{"schedules": [{"team": "a", "rotation": {"start": "2024-04-22T09:00:00", "handoff": "daily"}}]}`:                     "no members",
+		`{"schedules": [{"team": "a", "rotation": {"start": "yesterday", "handoff": "daily", "members": ["x"]}}]}`:             "invalid rotation start",
+		`{"schedules": [{"team": "a", "rotation": {"start": "2024-04-22T09:00:00", "handoff": "monthly", "members": ["x"]}}]}`: "invalid handoff",
+	}
+	for content, expected := range invalid {
+		invalidFile := createTestFile(t, content)
+		defer os.Remove(invalidFile)
+
+		_, err := loadOnCallSchedules(invalidFile)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing '%s', got: %v", expected, err)
+		}
+	}
+}
+
+func TestParseFlags_OnCallRequiresRoutes(t *testing.T) {
+	resetFlags()
+
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--oncall", testFile}
+
+	_, err := parseFlags()
+	if err == nil || !strings.Contains(err.Error(), "need a routing tree") {
+		t.Errorf("Expected error about missing routing tree, got: %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	pagerDutyEndpoint = "https://events.pagerduty.com/v2/enqueue"
+	opsgenieEndpoint  = "https://api.opsgenie.com/v2/alerts"
+)
+
+// PagerDutyEvent is a PagerDuty Events API v2 event
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"`
+}
+
+// PagerDutyPayload describes the alert of a trigger event
+type PagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp,omitempty"`
+	Component     string         `json:"component,omitempty"`
+	Group         string         `json:"group,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// OpsgenieAlert is an Opsgenie Alert API create request
+type OpsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Source      string            `json:"source"`
+	Entity      string            `json:"entity,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	Priority    string            `json:"priority"`
+}
+
+// OpsgenieClose is an Opsgenie Alert API close request
+type OpsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note"`
+}
+
+// pagingRequest is a single HTTP request to a paging provider
+type pagingRequest struct {
+	URL     string
+	Body    any
+	Headers map[string]string
+}
+
+// pagerDutySeverity maps an alert severity onto the PagerDuty severities
+func pagerDutySeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "error", "warning", "info":
+		return strings.ToLower(severity)
+	default:
+		return "info" // Unknown severities are scored as info as well
+	}
+}
+
+// opsgeniePriority maps an alert severity onto the Opsgenie priorities
+func opsgeniePriority(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return "P1"
+	case "error":
+		return "P2"
+	case "warning":
+		return "P3"
+	case "info":
+		return "P5"
+	default:
+		return "P5"
+	}
+}
+
+// buildPagerDutyEvents creates trigger events for the given alerts and
+// resolve events for the resolved ones. Alerts share a dedup key per identity.
+func buildPagerDutyEvents(trigger, resolve []Alert, routingKey string) []PagerDutyEvent {
+	events := make([]PagerDutyEvent, 0, len(trigger)+len(resolve))
+
+	for _, alert := range trigger {
+		events = append(events, PagerDutyEvent{
+			RoutingKey:  routingKey,
+			EventAction: "trigger",
+			DedupKey:    alert.Identity(),
+			Payload: &PagerDutyPayload{
+				Summary:   fmt.Sprintf("[%s] %s: %s", alert.Service, alert.ID, alert.Description),
+				Source:    alert.Service,
+				Severity:  pagerDutySeverity(alert.Severity),
+				Timestamp: alert.Timestamp.Format(time.RFC3339),
+				Component: alert.Component,
+				Group:     alert.Service,
+				Class:     alert.Metric,
+				CustomDetails: map[string]any{
+					"alert_id":  alert.ID,
+					"priority":  alert.Priority,
+					"value":     alert.Value,
+					"threshold": alert.Threshold,
+				},
+			},
+		})
+	}
+
+	for _, alert := range resolve {
+		events = append(events, PagerDutyEvent{
+			RoutingKey:  routingKey,
+			EventAction: "resolve",
+			DedupKey:    alert.Identity(),
+		})
+	}
+
+	return events
+}
+
+// buildOpsgenieAlert creates an Opsgenie alert aliased by the alert identity
+func buildOpsgenieAlert(alert Alert) OpsgenieAlert {
+	message := fmt.Sprintf("[%s] %s", alert.Service, alert.Description)
+	// Opsgenie limits messages to 130 characters; cut by rune so multi-byte
+	// characters stay whole
+	if runes := []rune(message); len(runes) > 130 {
+		message = string(runes[:127]) + "..."
+	}
+
+	return OpsgenieAlert{
+		Message:     message,
+		Alias:       alert.Identity(),
+		Description: alert.Description,
+		Source:      AppName,
+		Entity:      alert.Service + "/" + alert.Component,
+		Tags:        []string{alert.Severity, alert.Service, alert.Metric},
+		Details: map[string]string{
+			"alert_id":  alert.ID,
+			"priority":  fmt.Sprintf("%.2f", alert.Priority),
+			"value":     fmt.Sprintf("%.2f", alert.Value),
+			"threshold": fmt.Sprintf("%.2f", alert.Threshold),
+			"timestamp": alert.Timestamp.Format(time.RFC3339),
+		},
+		Priority: opsgeniePriority(alert.Severity),
+	}
+}
+
+// buildPagingRequests creates the HTTP requests for a paging provider
+func buildPagingRequests(provider, endpoint, key string, trigger, resolve []Alert) ([]pagingRequest, error) {
+	var requests []pagingRequest
+
+	switch provider {
+	case "pagerduty":
+		if endpoint == "" {
+			endpoint = pagerDutyEndpoint
+		}
+		for _, event := range buildPagerDutyEvents(trigger, resolve, key) {
+			requests = append(requests, pagingRequest{URL: endpoint, Body: event})
+		}
+	case "opsgenie":
+		if endpoint == "" {
+			endpoint = opsgenieEndpoint
+		}
+		headers := map[string]string{"Authorization": "GenieKey " + key}
+		for _, alert := range trigger {
+			requests = append(requests, pagingRequest{URL: endpoint, Body: buildOpsgenieAlert(alert), Headers: headers})
+		}
+		for _, alert := range resolve {
+			closeURL := fmt.Sprintf("%s/%s/close?identifierType=alias",
+				strings.TrimSuffix(endpoint, "/"), url.PathEscape(alert.Identity()))
+			body := OpsgenieClose{Source: AppName, Note: "Alert no longer present in export"}
+			requests = append(requests, pagingRequest{URL: closeURL, Body: body, Headers: headers})
+		}
+	default:
+		return nil, fmt.Errorf("invalid provider '%s'. Valid providers: pagerduty, opsgenie", provider)
+	}
+
+	return requests, nil
+}
+
+// filterByMinPriority keeps alerts with at least the given priority
+func (alerts Alerts) filterByMinPriority(minPriority float64) Alerts {
+	var filtered []Alert
+	for _, alert := range alerts.Alerts {
+		if alert.Priority >= minPriority {
+			filtered = append(filtered, alert)
+		}
+	}
+	return Alerts{Alerts: filtered}
+}
+
+func runExportCommand(args []string) error {
+	config := newConfig()
+	var previousFile, provider, endpoint, outputFile string
+	var minPriority float64
+	var retries int
+	fs := newCommandFlagSet("export", "-i <input-file> --provider <pagerduty|opsgenie> [OPTIONS]",
+		"Exports high priority alerts as PagerDuty Events API v2 events or Opsgenie alerts.\n"+
+			"The PagerDuty routing key is read from ENC_ALERTBUDDY_PAGERDUTY_ROUTING_KEY and\n"+
+			"the Opsgenie API key from ENC_ALERTBUDDY_OPSGENIE_API_KEY.")
+	registerInputFlags(fs, config)
+	registerAnalysisFlags(fs, config)
+	fs.StringVar(&provider, "provider", "pagerduty", "Paging provider to export to (pagerduty, opsgenie)")
+	fs.Float64Var(&minPriority, "min-priority", 50, "Only export alerts with at least this priority")
+	fs.StringVar(&previousFile, "previous", "", "Previous export; alerts no longer present are resolved")
+	fs.StringVar(&endpoint, "endpoint", "", "Endpoint to send events to (defaults to the provider's API)")
+	fs.StringVar(&outputFile, "o", "", "Write the events to this file instead of sending them")
+	fs.StringVar(&outputFile, "output", "", "Write the events to this file instead of sending them")
+	fs.IntVar(&retries, "retries", 3, "Number of retries for failed requests")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var key string
+	switch provider {
+	case "pagerduty":
+		key = os.Getenv("ENC_ALERTBUDDY_PAGERDUTY_ROUTING_KEY")
+	case "opsgenie":
+		key = os.Getenv("ENC_ALERTBUDDY_OPSGENIE_API_KEY")
+	default:
+		return fmt.Errorf("invalid provider '%s'. Valid providers: pagerduty, opsgenie", provider)
+	}
+	if key == "" && outputFile == "" {
+		return fmt.Errorf("no %s key set in the environment; use -o to write the events to disk instead", provider)
+	}
+
+	alerts, err := loadPreparedAlerts(config)
+	if err != nil {
+		return err
+	}
+	trigger := alerts.filterByMinPriority(minPriority)
+
+	var resolve []Alert
+	if previousFile != "" {
+		previous, err := loadPreparedFile(config, previousFile)
+		if err != nil {
+			return err
+		}
+		// Resolve what was exported in the previous run and isn't now, also
+		// when the alert is still present but dropped below --min-priority
+		resolve = compareAlerts(trigger, previous.filterByMinPriority(minPriority)).Resolved
+	}
+
+	// Events written to disk are never sent, so keep the routing key in their
+	// bodies out of the file, as the sender does with the Opsgenie header
+	bodyKey := key
+	if outputFile != "" && provider == "pagerduty" && key != "" {
+		bodyKey = "REDACTED"
+	}
+	requests, err := buildPagingRequests(provider, endpoint, bodyKey, trigger.Alerts, resolve)
+	if err != nil {
+		return err
+	}
+
+	var sink io.Writer
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("error creating output file '%s': %v", outputFile, err)
+		}
+		defer file.Close()
+		sink = file
+	}
+	sender := newWebhookSender(retries, time.Second, sink)
+
+	for _, request := range requests {
+		body, err := json.Marshal(request.Body)
+		if err != nil {
+			return fmt.Errorf("error encoding %s event: %v", provider, err)
+		}
+		if err := sender.send(request.URL, body, request.Headers); err != nil {
+			return err
+		}
+	}
+
+	action := "Sent"
+	if outputFile != "" {
+		action = "Wrote"
+	}
+	fmt.Printf("📟 %s %d %s events (%d triggered, %d resolved)\n",
+		action, len(requests), provider, len(trigger.Alerts), len(resolve))
+	return nil
+}
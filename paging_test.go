@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestBuildPagerDutyEvents(t *testing.T) {
+	alerts := createTestAlerts()
+	alerts.CalculateAllPriorities()
+
+	events := buildPagerDutyEvents(alerts.Alerts[:2], alerts.Alerts[3:], "routing-key")
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+
+	trigger := events[0]
+	if trigger.EventAction != "trigger" || trigger.RoutingKey != "routing-key" {
+		t.Errorf("Unexpected trigger event: %+v", trigger)
+	}
+	if trigger.DedupKey != "payment-processor/api-gateway/latency" {
+		t.Errorf("Expected dedup key from alert identity, got %s", trigger.DedupKey)
+	}
+	if trigger.Payload.Severity != "critical" || events[1].Payload.Severity != "warning" {
+		t.Errorf("Expected severities critical and warning, got %s and %s",
+			trigger.Payload.Severity, events[1].Payload.Severity)
+	}
+
+	resolve := events[2]
+	if resolve.EventAction != "resolve" || resolve.Payload != nil {
+		t.Errorf("Expected resolve event without payload, got %+v", resolve)
+	}
+
+	// Resolve events must not carry a payload key at all
+	body, _ := json.Marshal(resolve)
+	if strings.Contains(string(body), "payload") {
+		t.Errorf("Expected no payload in resolve event, got %s", body)
+	}
+}
+
+func TestSeverityMappings(t *testing.T) {
+	tests := []struct {
+		severity  string
+		pagerDuty string
+		opsgenie  string
+	}{
+		{"critical", "critical", "P1"},
+		{"Warning", "warning", "P3"},
+		{"info", "info", "P5"},
+		{"bogus", "info", "P5"},
+	}
+
+	for _, test := range tests {
+		if got := pagerDutySeverity(test.severity); got != test.pagerDuty {
+			t.Errorf("pagerDutySeverity(%s) = %s, expected %s", test.severity, got, test.pagerDuty)
+		}
+		if got := opsgeniePriority(test.severity); got != test.opsgenie {
+			t.Errorf("opsgeniePriority(%s) = %s, expected %s", test.severity, got, test.opsgenie)
+		}
+	}
+}
+
+func TestBuildPagingRequestsOpsgenie(t *testing.T) {
+	alerts := createTestAlerts()
+
+	requests, err := buildPagingRequests("opsgenie", "http://localhost:9/v2/alerts", "secret", alerts.Alerts[:1], alerts.Alerts[1:2])
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+
+	create := requests[0].Body.(OpsgenieAlert)
+	if create.Alias != "payment-processor/api-gateway/latency" || create.Priority != "P1" {
+		t.Errorf("Unexpected Opsgenie alert: %+v", create)
+	}
+	if requests[0].Headers["Authorization"] != "GenieKey secret" {
+		t.Errorf("Expected GenieKey authorization, got %s", requests[0].Headers["Authorization"])
+	}
+	if requests[1].URL != "http://localhost:9/v2/alerts/payment-processor%2Fdatabase%2Fcpu_usage/close?identifierType=alias" {
+		t.Errorf("Unexpected close URL: %s", requests[1].URL)
+	}
+
+	long := alerts.Alerts[0]
+	long.Description = strings.Repeat("ä", 200)
+	if message := buildOpsgenieAlert(long).Message; !utf8.ValidString(message) || utf8.RuneCountInString(message) != 130 {
+		t.Errorf("Expected a valid message of 130 characters, got %d: %q", utf8.RuneCountInString(message), message)
+	}
+
+	if _, err := buildPagingRequests("victorops", "", "", nil, nil); err == nil {
+		t.Error("Expected error for invalid provider, got nil")
+	}
+}
+
+func TestRunExportCommand_ToFile(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	outputFile := filepath.Join(t.TempDir(), "events.jsonl")
+	t.Setenv("ENC_ALERTBUDDY_PAGERDUTY_ROUTING_KEY", "routing-secret")
+
+	err := runExportCommand([]string{"-i", testFile, "--provider", "pagerduty", "--min-priority", "20", "-o", outputFile})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Expected output file, got: %v", err)
+	}
+	defer file.Close()
+
+	var records []HTTPRequestRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record HTTPRequestRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Expected JSON lines, got: %s", scanner.Text())
+		}
+		records = append(records, record)
+	}
+
+	// Only the critical test alert reaches priority 20
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(records))
+	}
+	if records[0].URL != "https://events.pagerduty.com/REDACTED" {
+		t.Errorf("Expected the redacted default PagerDuty endpoint, got %s", records[0].URL)
+	}
+	var event PagerDutyEvent
+	if err := json.Unmarshal(records[0].Body, &event); err != nil || event.Payload.Source != "test-service" {
+		t.Errorf("Unexpected event body: %s", records[0].Body)
+	}
+	if event.RoutingKey != "REDACTED" || strings.Contains(string(records[0].Body), "routing-secret") {
+		t.Errorf("Expected the routing key to be redacted, got %s", records[0].Body)
+	}
+}
+
+func TestRunExportCommand_ResolvesBelowMinPriority(t *testing.T) {
+	previousFile := createTestFile(t, testJSONContent)
+	defer os.Remove(previousFile)
+	// ALT-001 is still firing, but no longer reaches priority 20
+	testFile := createTestFile(t, strings.Replace(testJSONContent, `"value": 2300`, `"value": 1000`, 1))
+	defer os.Remove(testFile)
+	outputFile := filepath.Join(t.TempDir(), "events.jsonl")
+
+	err := runExportCommand([]string{"-i", testFile, "--previous", previousFile, "--min-priority", "20", "--output", outputFile})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Expected output file, got: %v", err)
+	}
+	var record HTTPRequestRecord
+	var event PagerDutyEvent
+	if err := json.Unmarshal(data, &record); err != nil || json.Unmarshal(record.Body, &event) != nil {
+		t.Fatalf("Expected a single event, got: %s", data)
+	}
+	if event.EventAction != "resolve" || event.DedupKey != "test-service/test-component/latency" {
+		t.Errorf("Expected ALT-001 to be resolved, got %+v", event)
+	}
+}
+
+func TestRunExportCommand_Weights(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	outputFile := filepath.Join(t.TempDir(), "events.jsonl")
+
+	// Without the severity weight, the critical test alert no longer reaches priority 20
+	err := runExportCommand([]string{"-i", testFile, "--min-priority", "20", "--severity-weight", "0", "-o", outputFile})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Expected output file, got: %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("Expected no events, got: %s", data)
+	}
+}
+
+func TestRunExportCommand_Settings(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	settingsFile := createTestFile(t, "weights:\n  severity: 0\n")
+	defer os.Remove(settingsFile)
+	outputFile := filepath.Join(t.TempDir(), "events.jsonl")
+
+	// Without the severity weight, the critical test alert no longer reaches priority 20
+	err := runExportCommand([]string{"--config", settingsFile, "-i", testFile, "--min-priority", "20", "-o", outputFile})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Expected output file, got: %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("Expected the weights from the config file to apply, got: %s", data)
+	}
+}
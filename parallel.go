@@ -0,0 +1,42 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// maxWorkers is how many goroutines share CPU-bound work such as parsing
+// files and calculating priorities
+var maxWorkers = runtime.GOMAXPROCS(0)
+
+// parallelChunk is the smallest number of items worth a goroutine of its own
+const parallelChunk = 1024
+
+// parallelFor calls work for every index below n, splitting the indexes into
+// contiguous chunks of at least minChunk that run on up to maxWorkers
+// goroutines. Small inputs run on the calling goroutine.
+func parallelFor(n, minChunk int, work func(start, end int)) {
+	workers := maxWorkers
+	if chunks := (n + minChunk - 1) / minChunk; chunks < workers {
+		workers = chunks
+	}
+	if workers <= 1 {
+		work(0, n)
+		return
+	}
+
+	var wg sync.WaitGroup
+	size := (n + workers - 1) / workers
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			work(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// StageKind is the phase of the pipeline a stage belongs to. Stages run
+// phase by phase, in this order, and in the order they were added within a
+// phase. Loading comes before the pipeline and rendering after it.
+type StageKind int
+
+const (
+	StageNormalize StageKind = iota // Rewrites alerts without dropping any, e.g. into a timezone
+	StageFilter                     // Drops or collapses alerts
+	StageEnrich                     // Annotates the remaining alerts
+	StageScore                      // Calculates priorities
+	StageAnnotate                   // Annotates the scored alerts, e.g. by routes that match on priority
+	StageSort                       // Orders alerts for rendering
+)
+
+// Blast radius scopes: which alerts the affected components of an alert are
+// counted among
+const (
+	BlastRadiusFiltered = "filtered" // The alerts left after the filters
+	BlastRadiusAll      = "all"      // All alerts that were loaded
+)
+
+var blastRadiusScopes = []string{BlastRadiusFiltered, BlastRadiusAll}
+
+// Stage is a named step of the pipeline. Run returns the alerts it keeps and
+// may update them in place.
+type Stage struct {
+	Name  string
+	Kind  StageKind
+	Empty string // Reported when the stage leaves no alerts
+	Run   func(alerts Alerts, run *PipelineRun) Alerts
+}
+
+// PipelineRun is what stages share during a run
+type PipelineRun struct {
+	Input   Alerts    // The alerts after the normalize stages, before any filter
+	Reports io.Writer // Where stages report what they did
+}
+
+// Pipeline prepares loaded alerts for rendering: normalize, filter, enrich,
+// score and sort
+type Pipeline struct {
+	stages  []Stage
+	Reports io.Writer // Reports of the stages; nil discards them
+}
+
+// NewPipeline returns a pipeline of the given stages
+func NewPipeline(stages ...Stage) *Pipeline {
+	return (&Pipeline{}).With(stages...)
+}
+
+// With adds stages, replacing the stages of the same name
+func (pipeline *Pipeline) With(stages ...Stage) *Pipeline {
+	for _, stage := range stages {
+		pipeline.Without(stage.Name)
+		pipeline.stages = append(pipeline.stages, stage)
+	}
+	return pipeline
+}
+
+// Without removes the stages of the given names
+func (pipeline *Pipeline) Without(names ...string) *Pipeline {
+	kept := pipeline.stages[:0]
+	for _, stage := range pipeline.stages {
+		if !contains(names, stage.Name) {
+			kept = append(kept, stage)
+		}
+	}
+	pipeline.stages = kept
+	return pipeline
+}
+
+// Stages returns the stages in the order they run
+func (pipeline *Pipeline) Stages() []Stage {
+	stages := append([]Stage(nil), pipeline.stages...)
+	sort.SliceStable(stages, func(i, j int) bool { return stages[i].Kind < stages[j].Kind })
+	return stages
+}
+
+// Run passes alerts through the stages. It stops at the first stage that
+// leaves no alerts, reporting why.
+func (pipeline *Pipeline) Run(alerts Alerts) Alerts {
+	run := &PipelineRun{Input: alerts, Reports: pipeline.Reports}
+	if run.Reports == nil {
+		run.Reports = io.Discard
+	}
+	if len(alerts.Alerts) == 0 {
+		return alerts
+	}
+
+	for _, stage := range pipeline.Stages() {
+		alerts = stage.Run(alerts, run)
+		if stage.Kind == StageNormalize {
+			run.Input = alerts
+		}
+		if len(alerts.Alerts) == 0 {
+			if stage.Empty != "" {
+				fmt.Fprintf(run.Reports, "⚠️  %s\n", stage.Empty)
+			}
+			return alerts
+		}
+	}
+	return alerts
+}
+
+// Load loads alerts from a file or directory and runs them through the
+// pipeline
+func (pipeline *Pipeline) Load(filename string, options ...LoadOptions) (Alerts, error) {
+	alerts, err := loadAlertsFromFile(filename, options...)
+	if err != nil {
+		return Alerts{}, err
+	}
+	return pipeline.Run(*alerts), nil
+}
+
+// ZoneStage moves timestamps to a timezone, so detection and trends report
+// their times there too
+func ZoneStage(location *time.Location) Stage {
+	return Stage{Name: "zone", Kind: StageNormalize, Run: func(alerts Alerts, run *PipelineRun) Alerts {
+		alerts.InZone(location)
+		return alerts
+	}}
+}
+
+// FieldFilterStage keeps the alerts whose fields match the filters
+func FieldFilterStage(filters map[string]string) Stage {
+	return Stage{Name: "filter", Kind: StageFilter, Empty: "No alerts match the filter.",
+		Run: func(alerts Alerts, run *PipelineRun) Alerts {
+			return alerts.FilterByFields(filters)
+		}}
+}
+
+// MinSeverityStage keeps the alerts at or above a severity
+func MinSeverityStage(model *SeverityModel, minimum string) Stage {
+	return Stage{Name: "min-severity", Kind: StageFilter, Empty: fmt.Sprintf("No alerts at or above %s severity.", minimum),
+		Run: func(alerts Alerts, run *PipelineRun) Alerts {
+			return alerts.FilterByMinSeverity(model, minimum)
+		}}
+}
+
+// MaintenanceStage suppresses or down-weights the alerts inside maintenance
+// windows
+func MaintenanceStage(windows []MaintenanceWindow) Stage {
+	return Stage{Name: "maintenance", Kind: StageFilter, Empty: "All alerts are suppressed by maintenance windows.",
+		Run: func(alerts Alerts, run *PipelineRun) Alerts {
+			remaining, results := alerts.ApplyMaintenance(windows)
+			showMaintenanceReport(run.Reports, results)
+			return remaining
+		}}
+}
+
+// DetectionStage collapses flapping alerts and flags storms. With a
+// detector name, it only keeps the alerts that detector flagged. It runs
+// before the time window, so storms are measured against the whole input.
+func DetectionStage(detectors DetectorConfig, detected string) Stage {
+	stage := Stage{Name: "detect", Kind: StageFilter, Run: func(alerts Alerts, run *PipelineRun) Alerts {
+		collapsed, result := alerts.DetectFlappingAndStorms(detectors)
+		showDetectionReport(run.Reports, result)
+		if detected == "" {
+			return collapsed
+		}
+		return collapsed.FilterByDetection(detected)
+	}}
+	if detected != "" {
+		stage.Empty = fmt.Sprintf("No %s alerts found.", detected)
+	}
+	return stage
+}
+
+// TimeWindowStage keeps the alerts from the last minutes
+func TimeWindowStage(minutes int) Stage {
+	return Stage{Name: "time-window", Kind: StageFilter, Empty: fmt.Sprintf("No alerts found in the last %d minutes.", minutes),
+		Run: func(alerts Alerts, run *PipelineRun) Alerts {
+			fmt.Fprintf(run.Reports, "🕒 Filtering alerts from the last %d minutes...\n", minutes)
+			return alerts.FilterByLastMinutes(minutes)
+		}}
+}
+
+// AnomalyStage scores how unusual each value is against its history
+func AnomalyStage(history AnomalyHistory, method string) Stage {
+	return Stage{Name: "anomaly", Kind: StageEnrich, Run: func(alerts Alerts, run *PipelineRun) Alerts {
+		alerts.ScoreAnomalies(history, method)
+		showAnomalyReport(run.Reports, alerts, history, method)
+		return alerts
+	}}
+}
+
+// OnCallStage annotates alerts with their team and on-call engineer. It runs
+// after scoring, as routes can match on priority.
+func OnCallStage(routes *Route, schedules map[string]*OnCallSchedule) Stage {
+	return Stage{Name: "oncall", Kind: StageAnnotate, Run: func(alerts Alerts, run *PipelineRun) Alerts {
+		alerts.AnnotateOnCall(routes, schedules)
+		return alerts
+	}}
+}
+
+// ScoreStage calculates priorities, counting affected components among the
+// alerts of the blast radius scope
+func ScoreStage(weights PriorityWeights, blastRadius string) Stage {
+	return Stage{Name: "score", Kind: StageScore, Run: func(alerts Alerts, run *PipelineRun) Alerts {
+		if blastRadius == BlastRadiusAll {
+			alerts.CalculateWeightedPrioritiesAmong(run.Input, weights)
+		} else {
+			alerts.CalculateAllWeightedPriorities(weights)
+		}
+		return alerts
+	}}
+}
+
+// SortStage sorts alerts by sort keys, priority first if there are none
+func SortStage(keys []SortKey, severities *SeverityModel) Stage {
+	return Stage{Name: "sort", Kind: StageSort, Run: func(alerts Alerts, run *PipelineRun) Alerts {
+		alerts.SortBy(keys, severities)
+		return alerts
+	}}
+}
+
+// TopStage takes the place of SortStage when only the first n alerts are
+// shown, selecting them without sorting the rest
+func TopStage(n int, keys []SortKey, severities *SeverityModel) Stage {
+	return Stage{Name: "sort", Kind: StageSort, Run: func(alerts Alerts, run *PipelineRun) Alerts {
+		alerts.SortTop(n, keys, severities)
+		return alerts
+	}}
+}
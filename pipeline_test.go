@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPipelineBlastRadius(t *testing.T) {
+	now := time.Now()
+	input := func() Alerts {
+		return Alerts{Alerts: []Alert{
+			{ID: "ALT-1", Timestamp: now.Add(-2 * time.Hour), Service: "web", Component: "web-1", Severity: "critical", Metric: "cpu", Value: 90, Threshold: 80},
+			{ID: "ALT-2", Timestamp: now.Add(-time.Minute), Service: "web", Component: "web-2", Severity: "critical", Metric: "cpu", Value: 90, Threshold: 80},
+		}}
+	}
+
+	// The alert outside the time window no longer adds to the blast radius
+	filtered := NewPipeline(TimeWindowStage(30), ScoreStage(PriorityWeights{}, BlastRadiusFiltered)).Run(input())
+	all := NewPipeline(TimeWindowStage(30), ScoreStage(PriorityWeights{}, BlastRadiusAll)).Run(input())
+	if len(filtered.Alerts) != 1 || len(all.Alerts) != 1 {
+		t.Fatalf("Expected only ALT-2 in the time window, got %+v and %+v", filtered.Alerts, all.Alerts)
+	}
+	weights := DefaultPriorityWeights()
+	if difference := all.Alerts[0].Priority - filtered.Alerts[0].Priority; difference != weights.Components {
+		t.Errorf("Expected the filtered-out component to add %.2f to the priority, got %.2f", weights.Components, difference)
+	}
+}
+
+func TestPipelineStages(t *testing.T) {
+	pipeline := NewPipeline(
+		SortStage(nil, nil),
+		ScoreStage(PriorityWeights{}, BlastRadiusFiltered),
+		TimeWindowStage(30),
+		ZoneStage(time.UTC),
+		OnCallStage(nil, nil),
+		FieldFilterStage(nil),
+	)
+	pipeline.With(TopStage(5, nil, nil)).Without("oncall")
+
+	var names []string
+	for _, stage := range pipeline.Stages() {
+		names = append(names, stage.Name)
+	}
+	if strings.Join(names, " ") != "zone time-window filter score sort" {
+		t.Errorf("Expected the stages by kind in the order they were added, got %v", names)
+	}
+}
+
+func TestPipelineRoutesOnPriority(t *testing.T) {
+	routes := &Route{Receiver: "default", Routes: []*Route{{Receiver: "urgent", MinPriority: 50}}}
+	alerts := Alerts{Alerts: []Alert{
+		{ID: "ALT-1", Service: "web", Component: "web-1", Severity: "critical", Metric: "cpu", Value: 500, Threshold: 100},
+		{ID: "ALT-2", Service: "db", Component: "db-1", Severity: "info", Metric: "disk", Value: 81, Threshold: 80},
+	}}
+
+	// Added before the score stage, the on-call stage still runs after it
+	routed := NewPipeline(OnCallStage(routes, nil), ScoreStage(PriorityWeights{}, BlastRadiusFiltered)).Run(alerts)
+	for _, alert := range routed.Alerts {
+		expected := "default"
+		if alert.Priority >= 50 {
+			expected = "urgent"
+		}
+		if alert.Team != expected {
+			t.Errorf("Expected %s with priority %.2f to be routed to %s, got %q", alert.ID, alert.Priority, expected, alert.Team)
+		}
+	}
+	if routed.Alerts[0].Team != "urgent" {
+		t.Errorf("Expected the critical alert to reach the min_priority route, got %+v", routed.Alerts[0])
+	}
+}
+
+func TestPipelineStopsWhenEmpty(t *testing.T) {
+	var reports bytes.Buffer
+	scored := false
+	pipeline := NewPipeline(
+		MinSeverityStage(defaultSeverities, "critical"),
+		Stage{Name: "score", Kind: StageScore, Run: func(alerts Alerts, run *PipelineRun) Alerts {
+			scored = true
+			return alerts
+		}},
+	)
+	pipeline.Reports = &reports
+
+	alerts := pipeline.Run(Alerts{Alerts: []Alert{{ID: "ALT-1", Severity: "info"}}})
+	if len(alerts.Alerts) != 0 || scored {
+		t.Errorf("Expected the pipeline to stop after the filter, got %+v", alerts.Alerts)
+	}
+	if !strings.Contains(reports.String(), "No alerts at or above critical severity.") {
+		t.Errorf("Expected the filter to report why, got %q", reports.String())
+	}
+}
+
+func TestConfigPipeline(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+
+	config := newConfig()
+	config.InputFile, config.BlastRadius = testFile, "everything"
+	if err := config.validate(); err == nil {
+		t.Error("Expected an error for an invalid blast radius")
+	}
+
+	config.BlastRadius, config.LastMinutes, config.MinSeverity = BlastRadiusAll, 30, "warning"
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var names []string
+	for _, stage := range config.pipeline().Stages() {
+		names = append(names, stage.Name)
+	}
+	if strings.Join(names, " ") != "min-severity time-window score sort" {
+		t.Errorf("Expected the time window before scoring, got %v", names)
+	}
+}
@@ -2,37 +2,71 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 )
 
 // PrettyPrint formats and prints a single alert
 func (alert Alert) PrettyPrint() {
-	fmt.Printf("┌─ Alert: %s ─┐\n", alert.ID)
-	fmt.Printf("│ Service:     %s\n", alert.Service)
-	fmt.Printf("│ Component:   %s\n", alert.Component)
-	fmt.Printf("│ Severity:    %s\n", alert.Severity)
-	fmt.Printf("│ Priority:    %.2f\n", alert.Priority)
-	fmt.Printf("│ Metric:      %s\n", alert.Metric)
-	fmt.Printf("│ Value:       %.2f (threshold: %.2f)\n", alert.Value, alert.Threshold)
-	fmt.Printf("│ Time:        %s\n", alert.Timestamp.Format("2006-01-02 15:04:05"))
-	fmt.Printf("│ Description: %s\n", alert.Description)
-	fmt.Println("└────────────────────────────────────────┘")
+	alert.PrettyPrintWith(os.Stdout, TimeDisplay{})
+}
+
+// PrettyPrintWith prints a single alert to w with its time rendered by display
+func (alert Alert) PrettyPrintWith(w io.Writer, display TimeDisplay) {
+	fmt.Fprintf(w, "┌─ Alert: %s ─┐\n", alert.ID)
+	fmt.Fprintf(w, "│ Service:     %s\n", alert.Service)
+	fmt.Fprintf(w, "│ Component:   %s\n", alert.Component)
+	fmt.Fprintf(w, "│ Severity:    %s\n", alert.Severity)
+	fmt.Fprintf(w, "│ Priority:    %.2f\n", alert.Priority)
+	fmt.Fprintf(w, "│ Metric:      %s\n", alert.Metric)
+	fmt.Fprintf(w, "│ Value:       %.2f (threshold: %.2f)\n", alert.Value, alert.Threshold)
+	fmt.Fprintf(w, "│ Time:        %s\n", display.Format(alert.Timestamp))
+	fmt.Fprintf(w, "│ Description: %s\n", alert.Description)
+	if len(alert.Labels) > 0 {
+		fmt.Fprintf(w, "│ Labels:      %s\n", formatLabels(alert.Labels))
+	}
+	if len(alert.Annotations) > 0 {
+		fmt.Fprintf(w, "│ Annotations: %s\n", formatLabels(alert.Annotations))
+	}
+	if alert.Maintenance != "" {
+		fmt.Fprintf(w, "│ Maintenance: %s\n", alert.Maintenance)
+	}
+	if alert.Team != "" {
+		fmt.Fprintf(w, "│ Team:        %s\n", alert.Team)
+		fmt.Fprintf(w, "│ On call:     %s\n", onCallOrUnknown(alert.OnCall))
+	}
+	if alert.Flapping {
+		fmt.Fprintf(w, "│ Flapping:    fired %d times\n", alert.FlapCount)
+	}
+	if alert.Storm {
+		fmt.Fprintln(w, "│ Storm:       part of an alert storm")
+	}
+	if alert.AnomalyScore > 0 {
+		fmt.Fprintf(w, "│ Anomaly:     %.2f\n", alert.AnomalyScore)
+	}
+	fmt.Fprintln(w, "└────────────────────────────────────────┘")
 }
 
 // PrettyPrint formats and prints all alerts in the collection
 func (alerts Alerts) PrettyPrint() {
-	fmt.Printf("📋 Alerts Summary: %d total\n", len(alerts.Alerts))
-	fmt.Println(strings.Repeat("=", 50))
+	alerts.PrettyPrintWith(os.Stdout, TimeDisplay{})
+}
+
+// PrettyPrintWith prints all alerts to w with their times rendered by display
+func (alerts Alerts) PrettyPrintWith(w io.Writer, display TimeDisplay) {
+	fmt.Fprintf(w, "📋 Alerts Summary: %d total\n", len(alerts.Alerts))
+	fmt.Fprintln(w, strings.Repeat("=", 50))
 
 	if len(alerts.Alerts) == 0 {
-		fmt.Println("No alerts to display.")
+		fmt.Fprintln(w, "No alerts to display.")
 		return
 	}
 
 	for i, alert := range alerts.Alerts {
-		fmt.Printf("\n[%d/%d]\n", i+1, len(alerts.Alerts))
-		alert.PrettyPrint()
+		fmt.Fprintf(w, "\n[%d/%d]\n", i+1, len(alerts.Alerts))
+		alert.PrettyPrintWith(w, display)
 	}
 
-	fmt.Printf("\n📊 Total: %d alerts displayed\n", len(alerts.Alerts))
+	fmt.Fprintf(w, "\n📊 Total: %d alerts displayed\n", len(alerts.Alerts))
 }
@@ -2,7 +2,6 @@ package main
 import (
 	"math"
 	"sort"
-	"strings"
 )
 
 // calculateDeviationPercentage calculates the percentage deviation from threshold
@@ -22,7 +21,8 @@ func calculateDeviationPercentage(value, threshold float64) float64 {
 	return percentage
 }
 
-// countAffectedComponents counts unique components for the same service and metric
+// countAffectedComponents counts unique components for the same service and
+// metric. It scans all alerts; to score many alerts, use a componentIndex.
 func (alerts Alerts) countAffectedComponents(targetAlert Alert) int {
 	componentSet := make(map[string]bool)
 
@@ -39,44 +39,170 @@ func (alerts Alerts) countAffectedComponents(targetAlert Alert) int {
 	return len(componentSet) + 1
 }
 
+// serviceMetric is the key alerts are grouped by to count affected components
+type serviceMetric struct {
+	Service string
+	Metric  string
+}
+
+// alertKey is an alert ID within a service and metric
+type alertKey struct {
+	serviceMetric
+	ID string
+}
+
+// componentIndex counts the components of every service and metric in a
+// single pass, so the affected components of any alert are found without
+// scanning all alerts again
+type componentIndex struct {
+	components map[serviceMetric]int // Distinct components per service and metric
+	own        map[alertKey]int      // Components only the alerts with an ID fire for
+}
+
+func newComponentIndex(alerts []Alert) *componentIndex {
+	alertsPerComponent := make(map[serviceMetric]map[string]int)
+	byID := make(map[alertKey][]string, len(alerts))
+	for _, alert := range alerts {
+		key := serviceMetric{Service: alert.Service, Metric: alert.Metric}
+		if alertsPerComponent[key] == nil {
+			alertsPerComponent[key] = make(map[string]int)
+		}
+		alertsPerComponent[key][alert.Component]++
+		id := alertKey{serviceMetric: key, ID: alert.ID}
+		byID[id] = append(byID[id], alert.Component)
+	}
+
+	index := &componentIndex{
+		components: make(map[serviceMetric]int, len(alertsPerComponent)),
+		own:        make(map[alertKey]int, len(byID)),
+	}
+	for key, components := range alertsPerComponent {
+		index.components[key] = len(components)
+	}
+	for id, components := range byID {
+		all := alertsPerComponent[id.serviceMetric]
+		if len(components) == 1 {
+			// The usual case: an ID fires once
+			if all[components[0]] == 1 {
+				index.own[id] = 1
+			}
+			continue
+		}
+		mine := make(map[string]int, len(components))
+		for _, component := range components {
+			mine[component]++
+		}
+		for component, count := range mine {
+			if all[component] == count {
+				index.own[id]++
+			}
+		}
+	}
+	return index
+}
+
+// affectedComponents counts the components of the other alerts (by ID) with
+// the same service and metric, plus one for the alert itself
+func (index *componentIndex) affectedComponents(alert Alert) int {
+	key := serviceMetric{Service: alert.Service, Metric: alert.Metric}
+	// Components only fired for by alerts with the alert's own ID don't count
+	count := index.components[key] - index.own[alertKey{serviceMetric: key, ID: alert.ID}]
+
+	// Always count at least 1 (the alert itself)
+	return count + 1
+}
+
+// PriorityWeights are the weights of the terms in the priority formula
+type PriorityWeights struct {
+	Severity   float64
+	Deviation  float64
+	Components float64
+	Anomaly    float64
+}
+
+// DefaultPriorityWeights returns the standard priority weights
+func DefaultPriorityWeights() PriorityWeights {
+	return PriorityWeights{Severity: 1.0, Deviation: 0.1, Components: 2.0, Anomaly: 1.0}
+}
+
+// withDefaults returns the default weights when none are set
+func (weights PriorityWeights) withDefaults() PriorityWeights {
+	if weights == (PriorityWeights{}) {
+		return DefaultPriorityWeights()
+	}
+	return weights
+}
+
 // CalculatePriority calculates and sets the priority score for an alert
 func (alert *Alert) CalculatePriority(allAlerts Alerts) {
-	// 1. Severity score (critical=10, warning=5, info=1)
-	var severityScore float64
-	switch strings.ToLower(alert.Severity) {
-	case "critical":
-		severityScore = 10.0
-	case "warning":
-		severityScore = 5.0
-	case "info":
-		severityScore = 1.0
-	default:
-		severityScore = 1.0 // Default to info level
+	alert.CalculateWeightedPriority(allAlerts, DefaultPriorityWeights())
+}
+
+// CalculateWeightedPriority calculates and sets the priority score for an
+// alert using the given weights
+func (alert *Alert) CalculateWeightedPriority(allAlerts Alerts, weights PriorityWeights) {
+	alert.calculatePriority(allAlerts.countAffectedComponents(*alert), weights)
+}
+
+// calculatePriority sets the priority score of an alert, given the number of
+// components affected along with it
+func (alert *Alert) calculatePriority(components int, weights PriorityWeights) {
+	// 1. Severity score from the severity ladder (by default critical=10,
+	// warning=5, info=1, and unknown severities score as info)
+	severityScore := alert.severityScore
+	if severityScore == 0 {
+		severityScore = defaultSeverities.Level(alert.Severity).Score
 	}
 
 	// 2. Deviation from threshold (percentage)
 	deviationPercentage := calculateDeviationPercentage(alert.Value, alert.Threshold)
 
 	// 3. Number of affected components
-	affectedComponents := float64(allAlerts.countAffectedComponents(*alert))
+	affectedComponents := float64(components)
 
 	// Calculate priority score using weighted formula
-	// Priority = (Severity * 1.0) + (Deviation% * 0.1) + (Components * 2.0)
-	priority := (severityScore * 1.0) + (deviationPercentage * 0.1) + (affectedComponents * 2.0)
+	// Priority = (Severity * 1.0) + (Deviation% * 0.1) + (Components * 2.0) + (Anomaly * 1.0)
+	priority := (severityScore * weights.Severity) + (deviationPercentage * weights.Deviation) +
+		(affectedComponents * weights.Components) + (alert.AnomalyScore * weights.Anomaly)
+
+	// Alerts inside a down-weighting maintenance window count for less
+	if alert.maintenanceFactor > 0 {
+		priority *= alert.maintenanceFactor
+	}
 
 	alert.Priority = math.Round(priority*100) / 100 // Round to 2 decimal places
 }
 
 // CalculateAllPriorities calculates priority scores for all alerts
 func (alerts *Alerts) CalculateAllPriorities() {
-	for i := range alerts.Alerts {
-		alerts.Alerts[i].CalculatePriority(*alerts)
-	}
+	alerts.CalculateAllWeightedPriorities(DefaultPriorityWeights())
+}
+
+// CalculateAllWeightedPriorities calculates priority scores for all alerts
+// using the given weights. The affected components are counted up front and
+// large sets of alerts are scored in parallel.
+func (alerts *Alerts) CalculateAllWeightedPriorities(weights PriorityWeights) {
+	alerts.CalculateWeightedPrioritiesAmong(*alerts, weights)
+}
+
+// CalculateWeightedPrioritiesAmong calculates priority scores for all alerts,
+// counting the affected components among others instead, e.g. among all
+// alerts before some were filtered out
+func (alerts *Alerts) CalculateWeightedPrioritiesAmong(others Alerts, weights PriorityWeights) {
+	weights = weights.withDefaults()
+	index := newComponentIndex(others.Alerts)
+	parallelFor(len(alerts.Alerts), parallelChunk, func(start, end int) {
+		for i := start; i < end; i++ {
+			alert := &alerts.Alerts[i]
+			alert.calculatePriority(index.affectedComponents(*alert), weights)
+		}
+	})
 }
 
-// SortByPriority sorts alerts by priority score in descending order (highest first)
+// SortByPriority sorts alerts by priority score in descending order (highest first).
+// Alerts with the same priority keep their input order.
 func (alerts *Alerts) SortByPriority() {
-	sort.Slice(alerts.Alerts, func(i, j int) bool {
+	sort.SliceStable(alerts.Alerts, func(i, j int) bool {
 		return alerts.Alerts[i].Priority > alerts.Alerts[j].Priority
 	})
 }
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// generateAlerts returns n alerts spread over a few services, metrics and
+// components, like a large export
+func generateAlerts(n int) Alerts {
+	options := testGeneratorOptions(n)
+	options.Services = 50
+	options.Components = 200
+	alerts, _ := GenerateAlerts(options)
+	return alerts
+}
+
+// naivePriorities scores alerts by scanning all alerts for every alert, as
+// CalculateAllPriorities used to
+func naivePriorities(alerts *Alerts) {
+	for i := range alerts.Alerts {
+		alerts.Alerts[i].CalculateWeightedPriority(*alerts, DefaultPriorityWeights())
+	}
+}
+
+func TestParallelPrioritiesMatchNaive(t *testing.T) {
+	defer func(workers int) { maxWorkers = workers }(maxWorkers)
+	maxWorkers = 4
+
+	alerts := generateAlerts(5000)
+	// Repeated IDs, as in overlapping exports, are not counted as others
+	alerts.Alerts[1].ID, alerts.Alerts[1].Service, alerts.Alerts[1].Metric = "ALT-0", alerts.Alerts[0].Service, alerts.Alerts[0].Metric
+	alerts.Alerts[2].Component = ""
+
+	expected := Alerts{Alerts: append([]Alert(nil), alerts.Alerts...)}
+	naivePriorities(&expected)
+	alerts.CalculateAllPriorities()
+
+	for i := range alerts.Alerts {
+		if alerts.Alerts[i].Priority != expected.Alerts[i].Priority {
+			t.Fatalf("Expected %s to have priority %.2f, got %.2f",
+				alerts.Alerts[i].ID, expected.Alerts[i].Priority, alerts.Alerts[i].Priority)
+		}
+	}
+}
+
+func TestComponentIndexOwnID(t *testing.T) {
+	alerts := []Alert{
+		{ID: "ALT-1", Service: "web", Metric: "cpu", Component: "a"},
+		{ID: "ALT-1", Service: "web", Metric: "cpu", Component: "b"},
+		{ID: "ALT-2", Service: "web", Metric: "cpu", Component: "b"},
+		{ID: "ALT-3", Service: "web", Metric: "cpu", Component: "c"},
+	}
+	index := newComponentIndex(alerts)
+	// ALT-1 is the only alert for a, but b is also fired for by ALT-2
+	if count := index.affectedComponents(alerts[0]); count != 3 {
+		t.Errorf("Expected b and c plus the alert itself, got %d", count)
+	}
+	if count := index.affectedComponents(alerts[3]); count != 3 {
+		t.Errorf("Expected a and b plus the alert itself, got %d", count)
+	}
+}
+
+func BenchmarkPriorities(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000, 500000} {
+		alerts := generateAlerts(size)
+		b.Run(fmt.Sprintf("parallel/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				alerts.CalculateAllPriorities()
+			}
+		})
+		b.Run(fmt.Sprintf("one-worker/%d", size), func(b *testing.B) {
+			defer func(workers int) { maxWorkers = workers }(maxWorkers)
+			maxWorkers = 1
+			for i := 0; i < b.N; i++ {
+				alerts.CalculateAllPriorities()
+			}
+		})
+		if size <= 10000 {
+			b.Run(fmt.Sprintf("naive/%d", size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					naivePriorities(&alerts)
+				}
+			})
+		}
+	}
+}
+
+// sharedIDAlerts returns n alerts of one service and metric over a few
+// components, all with the same ID or, if id is empty, without one
+func sharedIDAlerts(n int, id string) Alerts {
+	alerts := Alerts{Alerts: make([]Alert, n)}
+	for i := range alerts.Alerts {
+		alerts.Alerts[i] = Alert{ID: id, Service: "web", Metric: "cpu", Component: fmt.Sprintf("web-%d", i%20), Severity: "warning"}
+	}
+	return alerts
+}
+
+func TestSharedIDPrioritiesMatchNaive(t *testing.T) {
+	alerts := sharedIDAlerts(300, "")
+	// A few other IDs share components with the alerts without one
+	alerts.Alerts[0].ID, alerts.Alerts[1].ID, alerts.Alerts[2].Component = "ALT-1", "ALT-1", "web-only"
+
+	expected := Alerts{Alerts: append([]Alert(nil), alerts.Alerts...)}
+	naivePriorities(&expected)
+	alerts.CalculateAllPriorities()
+
+	for i := range alerts.Alerts {
+		if alerts.Alerts[i].Priority != expected.Alerts[i].Priority {
+			t.Fatalf("Expected alert %d to have priority %.2f, got %.2f", i, expected.Alerts[i].Priority, alerts.Alerts[i].Priority)
+		}
+	}
+}
+
+func BenchmarkPrioritiesSharedID(b *testing.B) {
+	for _, size := range []int{800, 100000} {
+		for name, id := range map[string]string{"no-id": "", "same-id": "ALT-1"} {
+			alerts := sharedIDAlerts(size, id)
+			b.Run(fmt.Sprintf("%s/%d", name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					alerts.CalculateAllPriorities()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// rankingDimensions are the alert fields that summary rankings cover
+var rankingDimensions = []string{"service", "component", "metric"}
+
+// rankingMetrics are what entries can be ranked by
+var rankingMetrics = []string{"count", "priority", "critical"}
+
+// parseRankMetrics parses a --rank-by list of ranking metrics. "all"
+// ranks by every metric.
+func parseRankMetrics(value string) ([]string, error) {
+	var metrics []string
+	for _, metric := range splitList(strings.ToLower(value)) {
+		switch {
+		case metric == "all":
+			return rankingMetrics, nil
+		case !contains(rankingMetrics, metric):
+			return nil, fmt.Errorf("invalid rank-by '%s'. Valid values: %s, all", metric, strings.Join(rankingMetrics, ", "))
+		case !contains(metrics, metric):
+			metrics = append(metrics, metric)
+		}
+	}
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("no ranking metrics in '%s'", value)
+	}
+	return metrics, nil
+}
+
+// RankEntry is one value of a dimension with its alert totals
+type RankEntry struct {
+	Name     string  `json:"name"`
+	Count    int     `json:"count"`
+	Priority float64 `json:"priority"` // Summed priority of the entry's alerts
+	Critical int     `json:"critical"`
+	Share    float64 `json:"share"` // Percentage of the total of the ranking metric
+}
+
+// Ranking is the top entries of a dimension by one metric
+type Ranking struct {
+	Dimension string      `json:"dimension"`
+	By        string      `json:"by"`
+	Entries   []RankEntry `json:"entries"`
+}
+
+// value returns the entry's value for a ranking metric
+func (entry RankEntry) value(by string) float64 {
+	switch by {
+	case "priority":
+		return entry.Priority
+	case "critical":
+		return float64(entry.Critical)
+	default:
+		return float64(entry.Count)
+	}
+}
+
+// Rank returns the top entries of a dimension by count, summed priority or
+// critical count. Ties are broken by name; top <= 0 returns all entries.
+// Entries without any critical alerts are left out of critical rankings.
+func (alerts Alerts) Rank(dimension, by string, top int) Ranking {
+	entries := make(map[string]*RankEntry)
+	for _, alert := range alerts.Alerts {
+		name, ok := alertFieldValue(alert, dimension)
+		if !ok {
+			continue
+		}
+		entry, exists := entries[name]
+		if !exists {
+			entry = &RankEntry{Name: name}
+			entries[name] = entry
+		}
+		entry.Count++
+		entry.Priority += alert.Priority
+		if strings.ToLower(alert.Severity) == "critical" {
+			entry.Critical++
+		}
+	}
+
+	ranking := Ranking{Dimension: dimension, By: by}
+	var total float64
+	for _, entry := range entries {
+		total += entry.value(by)
+		if by == "critical" && entry.Critical == 0 {
+			continue
+		}
+		ranking.Entries = append(ranking.Entries, *entry)
+	}
+
+	sort.Slice(ranking.Entries, func(i, j int) bool {
+		a, b := ranking.Entries[i].value(by), ranking.Entries[j].value(by)
+		if a != b {
+			return a > b
+		}
+		return ranking.Entries[i].Name < ranking.Entries[j].Name
+	})
+	if top > 0 && len(ranking.Entries) > top {
+		ranking.Entries = ranking.Entries[:top]
+	}
+
+	for i := range ranking.Entries {
+		if total > 0 {
+			ranking.Entries[i].Share = ranking.Entries[i].value(by) / total * 100
+		}
+	}
+
+	return ranking
+}
+
+// rankingTitles describe rankings in headings
+var rankingTitles = map[string]string{
+	"count":    "Alert Count",
+	"priority": "Total Priority",
+	"critical": "Critical Alerts",
+}
+
+// rankingEmojis head the rankings of each dimension
+var rankingEmojis = map[string]string{
+	"service":   "🏢",
+	"component": "🧩",
+	"metric":    "📏",
+}
+
+func showRanking(w io.Writer, ranking Ranking, top int) {
+	fmt.Fprintf(w, "\n%s Top %d %ss by %s:\n", rankingEmojis[ranking.Dimension], top,
+		strings.Title(ranking.Dimension), rankingTitles[ranking.By])
+	if len(ranking.Entries) == 0 {
+		fmt.Fprintln(w, "  None")
+		return
+	}
+	for i, entry := range ranking.Entries {
+		fmt.Fprintf(w, "  %d. %s: %d alerts, priority %.2f, %d critical (%.1f%%)\n",
+			i+1, entry.Name, entry.Count, entry.Priority, entry.Critical, entry.Share)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func createRankingAlerts() Alerts {
+	return Alerts{Alerts: []Alert{
+		{ID: "ALT-1", Service: "web", Component: "frontend", Metric: "errors", Severity: "warning", Priority: 10},
+		{ID: "ALT-2", Service: "web", Component: "frontend", Metric: "latency", Severity: "info", Priority: 5},
+		{ID: "ALT-3", Service: "db", Component: "primary", Metric: "latency", Severity: "critical", Priority: 40},
+		{ID: "ALT-4", Service: "cache", Component: "redis", Metric: "memory", Severity: "warning", Priority: 10},
+		{ID: "ALT-5", Service: "api", Component: "gateway", Metric: "errors", Severity: "Critical", Priority: 15},
+		{ID: "ALT-6", Service: "api", Component: "gateway", Metric: "errors", Severity: "info", Priority: 0},
+	}}
+}
+
+func TestRank(t *testing.T) {
+	alerts := createRankingAlerts()
+
+	byCount := alerts.Rank("service", "count", 3)
+	expected := []string{"api", "web", "cache"} // api and web tie on count, then cache and db tie
+	if len(byCount.Entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(byCount.Entries))
+	}
+	for i, name := range expected {
+		if byCount.Entries[i].Name != name {
+			t.Errorf("Expected %s at rank %d by count, got %s", name, i+1, byCount.Entries[i].Name)
+		}
+	}
+	if share := byCount.Entries[0].Share; share < 33.3 || share > 33.4 {
+		t.Errorf("Expected api to have a third of all alerts, got %.2f%%", share)
+	}
+
+	byPriority := alerts.Rank("service", "priority", 0)
+	if len(byPriority.Entries) != 4 || byPriority.Entries[0].Name != "db" || byPriority.Entries[0].Share != 50 {
+		t.Errorf("Expected db first with half of the priority, got %+v", byPriority.Entries)
+	}
+	if byPriority.Entries[1].Name != "api" || byPriority.Entries[2].Name != "web" {
+		t.Errorf("Expected api and web to be ordered by name on a priority tie, got %+v", byPriority.Entries)
+	}
+
+	byCritical := alerts.Rank("metric", "critical", 5)
+	if len(byCritical.Entries) != 2 || byCritical.Entries[0].Name != "errors" || byCritical.Entries[1].Name != "latency" {
+		t.Errorf("Expected only metrics with critical alerts, got %+v", byCritical.Entries)
+	}
+}
+
+func TestParseFlags_InvalidRanking(t *testing.T) {
+	resetFlags()
+
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--rank-by=volume"}
+	if _, err := parseFlags(); err == nil {
+		t.Error("Expected error for invalid rank-by, got nil")
+	}
+
+	resetFlags()
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--rank-top=0"}
+	if _, err := parseFlags(); err == nil {
+		t.Error("Expected error for rank-top 0, got nil")
+	}
+}
+
+func TestParseRankMetrics(t *testing.T) {
+	for value, expected := range map[string][]string{
+		"count":                    {"count"},
+		"Priority, critical,count": {"priority", "critical", "count"},
+		"count,count":              {"count"},
+		"all":                      rankingMetrics,
+	} {
+		metrics, err := parseRankMetrics(value)
+		if err != nil || fmt.Sprint(metrics) != fmt.Sprint(expected) {
+			t.Errorf("Expected %q to give %v, got %v, %v", value, expected, metrics, err)
+		}
+	}
+	for _, value := range []string{"", ",", "count,volume"} {
+		if _, err := parseRankMetrics(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
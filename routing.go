@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// RoutingConfig is the top level of a routing file
+type RoutingConfig struct {
+	Route *Route `json:"route"`
+}
+
+// Route is a node in the routing tree. An alert matching a route is handed
+// to its child routes; the first matching child wins unless it sets
+// Continue. If no child matches, the alert goes to the route's receiver.
+type Route struct {
+	Receiver    string            `json:"receiver,omitempty"`     // Team receiving the alert, inherited from the parent if empty
+	Match       map[string]string `json:"match,omitempty"`        // Alert field -> glob pattern, alternatives separated by "|"
+	MinPriority float64           `json:"min_priority,omitempty"` // Only match alerts with at least this priority
+	Continue    bool              `json:"continue,omitempty"`     // Keep evaluating sibling routes after a match
+	Routes      []*Route          `json:"routes,omitempty"`
+}
+
+func loadRoutingConfig(filename string) (*Route, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading routing file '%s': %v", filename, err)
+	}
+
+	var config RoutingConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing routing config from '%s': %v", filename, err)
+	}
+
+	if config.Route == nil {
+		return nil, fmt.Errorf("no route found in routing file '%s'", filename)
+	}
+	if config.Route.Receiver == "" {
+		return nil, fmt.Errorf("the root route in '%s' needs a default receiver", filename)
+	}
+	if err := config.Route.validate(); err != nil {
+		return nil, fmt.Errorf("invalid route in '%s': %v", filename, err)
+	}
+
+	return config.Route, nil
+}
+
+func (r *Route) validate() error {
+	for field, pattern := range r.Match {
+		if !isGroupableField(field) {
+			return fmt.Errorf("invalid match field '%s'. Valid fields: %s",
+				field, groupableFieldsHelp())
+		}
+		for _, alternative := range strings.Split(pattern, "|") {
+			if _, err := path.Match(alternative, ""); err != nil {
+				return fmt.Errorf("invalid pattern '%s' for field '%s'", pattern, field)
+			}
+		}
+	}
+
+	for _, child := range r.Routes {
+		if err := child.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether the alert satisfies the route's own matchers
+func (r *Route) Matches(alert Alert) bool {
+	if alert.Priority < r.MinPriority {
+		return false
+	}
+
+	for field, pattern := range r.Match {
+		value, ok := alertFieldValue(alert, field)
+		if !ok || !matchesPattern(pattern, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesPattern matches a value against "|"-separated glob alternatives
+func matchesPattern(pattern, value string) bool {
+	for _, alternative := range strings.Split(pattern, "|") {
+		if ok, _ := path.Match(alternative, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Receivers returns the receivers an alert is routed to, in tree order
+func (r *Route) Receivers(alert Alert) []string {
+	return r.receivers(alert, "")
+}
+
+func (r *Route) receivers(alert Alert, inherited string) []string {
+	receiver := r.Receiver
+	if receiver == "" {
+		receiver = inherited
+	}
+
+	var receivers []string
+	for _, child := range r.Routes {
+		if !child.Matches(alert) {
+			continue
+		}
+		receivers = append(receivers, child.receivers(alert, receiver)...)
+		if !child.Continue {
+			break
+		}
+	}
+
+	if len(receivers) == 0 {
+		return []string{receiver}
+	}
+	return receivers
+}
+
+// RouteAlerts groups alerts by receiver. With continue routes, an alert can
+// appear under more than one receiver.
+func (alerts Alerts) RouteAlerts(root *Route) map[string]Alerts {
+	routed := make(map[string]Alerts)
+
+	for _, alert := range alerts.Alerts {
+		seen := make(map[string]bool)
+		for _, receiver := range root.Receivers(alert) {
+			if seen[receiver] {
+				continue
+			}
+			seen[receiver] = true
+
+			group := routed[receiver]
+			group.Alerts = append(group.Alerts, alert)
+			routed[receiver] = group
+		}
+	}
+
+	return routed
+}
+
+func prettyPrintRouted(routed map[string]Alerts, top int) {
+	receivers := make([]string, 0, len(routed))
+	for receiver := range routed {
+		receivers = append(receivers, receiver)
+	}
+	sort.Strings(receivers)
+
+	fmt.Println("🧭 Alerts routed by team:")
+	fmt.Println(strings.Repeat("=", 60))
+
+	for _, receiver := range receivers {
+		group := routed[receiver]
+		group.SortByPriority()
+
+		fmt.Printf("\n👥 %s: %d alerts\n", receiver, len(group.Alerts))
+		fmt.Println(strings.Repeat("-", 40))
+
+		shown := len(group.Alerts)
+		if top > 0 && shown > top {
+			shown = top
+		}
+		for i, alert := range group.Alerts[:shown] {
+			fmt.Printf("  [%d] Priority: %.2f | %s | %s | %s/%s\n",
+				i+1, alert.Priority, alert.Severity, alert.ID, alert.Service, alert.Component)
+		}
+		if len(group.Alerts) > shown {
+			fmt.Printf("  ... and %d more alerts\n", len(group.Alerts)-shown)
+		}
+	}
+
+	fmt.Printf("\n📈 Total teams: %d\n", len(routed))
+}
+
+func runRouteCommand(args []string) error {
+	config := newConfig()
+	var top int
+	fs := newCommandFlagSet("route", "-i <input-file> --routes <routes-file> [--top N] [OPTIONS]",
+		"Shows which team each alert is routed to and the top alerts per team.")
+	registerInputFlags(fs, config)
+	registerAnalysisFlags(fs, config)
+	fs.IntVar(&top, "top", 5, "Number of alerts to show per team (0 shows all)")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if config.RoutesFile == "" {
+		return fmt.Errorf("routing file is required. Use --routes to specify it")
+	}
+	if top < 0 {
+		return fmt.Errorf("top must be a positive number")
+	}
+
+	alerts, err := loadPreparedAlerts(config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📊 Loaded %d alerts from %s\n\n", len(alerts.Alerts), config.InputFile)
+	prettyPrintRouted(alerts.RouteAlerts(config.routes), top)
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func createTestRoutes() *Route {
+	return &Route{
+		Receiver: "platform-team",
+		Routes: []*Route{
+			{
+				Receiver: "payments-team",
+				Match:    map[string]string{"service": "payment-*"},
+				Routes: []*Route{
+					{Receiver: "payments-oncall", Match: map[string]string{"severity": "critical"}},
+				},
+			},
+			{Receiver: "sre-team", Match: map[string]string{"severity": "critical|warning"}, MinPriority: 20, Continue: true},
+			{Receiver: "auth-team", Match: map[string]string{"service": "user-*"}},
+		},
+	}
+}
+
+func TestRouteReceivers(t *testing.T) {
+	root := createTestRoutes()
+
+	tests := []struct {
+		alert    Alert
+		expected []string
+	}{
+		{Alert{Service: "payment-processor", Severity: "critical"}, []string{"payments-oncall"}},
+		{Alert{Service: "payment-processor", Severity: "warning"}, []string{"payments-team"}},
+		{Alert{Service: "user-authentication", Severity: "critical", Priority: 30}, []string{"sre-team", "auth-team"}},
+		{Alert{Service: "user-authentication", Severity: "critical", Priority: 10}, []string{"auth-team"}},
+		{Alert{Service: "search", Severity: "info"}, []string{"platform-team"}},
+	}
+
+	for _, test := range tests {
+		receivers := root.Receivers(test.alert)
+		if !reflect.DeepEqual(receivers, test.expected) {
+			t.Errorf("Receivers(%s/%s/%.0f) = %v, expected %v",
+				test.alert.Service, test.alert.Severity, test.alert.Priority, receivers, test.expected)
+		}
+	}
+}
+
+func TestRouteAlerts(t *testing.T) {
+	alerts := createTestAlerts()
+	alerts.CalculateAllPriorities()
+
+	routed := alerts.RouteAlerts(createTestRoutes())
+
+	if len(routed["payments-oncall"].Alerts) != 1 {
+		t.Errorf("Expected 1 alert for payments-oncall, got %d", len(routed["payments-oncall"].Alerts))
+	}
+	if len(routed["payments-team"].Alerts) != 1 {
+		t.Errorf("Expected 1 alert for payments-team, got %d", len(routed["payments-team"].Alerts))
+	}
+	if len(routed["auth-team"].Alerts) != 2 {
+		t.Errorf("Expected 2 alerts for auth-team, got %d", len(routed["auth-team"].Alerts))
+	}
+	// The critical session-manager alert continues past the sre route
+	if len(routed["sre-team"].Alerts) != 1 {
+		t.Errorf("Expected 1 alert for sre-team, got %d", len(routed["sre-team"].Alerts))
+	}
+}
+
+func TestLoadRoutingConfig(t *testing.T) {
+	valid := `{"route": {"receiver": "default", "routes": [{"receiver": "db", "match": {"component": "database"}}]}}`
+	testFile := createTestFile(t, valid)
+	defer os.Remove(testFile)
+
+	root, err := loadRoutingConfig(testFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if root.Receiver != "default" || len(root.Routes) != 1 {
+		t.Errorf("Unexpected routing tree: %+v", root)
+	}
+
+	invalid := map[string]string{
+		`{"route": {"routes": []}}`: "default receiver",
+		`{"route": {"receiver": "x", "routes": [{"match": {"owner": "me"}}]}}`:        "invalid match field",
+		`{"route": {"receiver": "x", "routes": [{"match": {"service": "[broken"}}]}}`: "invalid pattern",
+		`{"routes": []}`: "no route found",
+	}
+	for content, expected := range invalid {
+		invalidFile := createTestFile(t, content)
+		defer os.Remove(invalidFile)
+
+		_, err := loadRoutingConfig(invalidFile)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing '%s' for %s, got: %v", expected, content, err)
+		}
+	}
+}
+
+func TestRunRouteCommand_Settings(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	routesFile := createTestFile(t, `{"route": {"receiver": "default"}}`)
+	defer os.Remove(routesFile)
+	// routing is the config file name of --routes
+	settingsFile := createTestFile(t, "input: "+testFile+"\nrouting: "+routesFile+"\n")
+	defer os.Remove(settingsFile)
+
+	if err := runRouteCommand([]string{"--config", settingsFile}); err != nil {
+		t.Errorf("Expected the input and routing tree from the config file, got: %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// schemaDialect is the JSON Schema version the generated schema follows
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema used to describe input files
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	pattern              *regexp.Regexp         // Compiled from Pattern by compilePatterns
+	timestamp            bool                   // Checked with parseTimestamp, which JSON Schema can't express
+}
+
+// schemaFields adds descriptions and constraints to input fields, by JSON name
+var schemaFields = map[string]JSONSchema{
+	"alerts":      {Description: "Alerts of the export", MinItems: intPtr(1)},
+	"id":          {Description: "Unique ID of the alert", MinLength: intPtr(1)},
+	"timestamp":   {Description: "When the alert fired: RFC 3339, also with offsets such as +0300 or a space instead of the T, RFC 1123 with a numeric zone, or epoch seconds or milliseconds as a number or string"},
+	"service":     {Description: "Service the alert is about"},
+	"component":   {Description: "Component of the service, e.g. a database or a host"},
+	"severity":    {Description: "Severity or alias from the severity ladder, in any case"},
+	"metric":      {Description: "Metric that crossed its threshold"},
+	"value":       {Description: "Value of the metric when the alert fired"},
+	"threshold":   {Description: "Threshold the value is compared to"},
+	"description": {Description: "Human readable description"},
+	"labels":      {Description: "Identifying keys such as region or cluster. Other keys of an alert are read as labels too."},
+	"annotations": {Description: "Descriptive keys such as runbook_url. Other keys named summary, runbook, dashboard or ending in _url are read as annotations too."},
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+// inputSchema generates the JSON Schema of input files from the schema tags
+// of Alerts and Alert, accepting the severities of the given ladder
+func inputSchema(severities *SeverityModel) *JSONSchema {
+	schema := schemaForType(reflect.TypeOf(Alerts{}))
+	schema.Schema = schemaDialect
+	schema.Title = AppName + " alerts"
+	schema.Description = "Alerts read with -i/--input"
+	schema.Properties["alerts"].Items.Properties["severity"].Pattern = casePattern(severities.allNames())
+	return schema
+}
+
+// compilePatterns compiles the patterns of the schema and its subschemas,
+// so validate matches values without compiling them again
+func (schema *JSONSchema) compilePatterns() error {
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid schema pattern '%s': %v", schema.Pattern, err)
+		}
+		schema.pattern = pattern
+	}
+	subschemas := append([]*JSONSchema{schema.Items, schema.AdditionalProperties}, schema.AnyOf...)
+	for _, property := range schema.Properties {
+		subschemas = append(subschemas, property)
+	}
+	for _, subschema := range subschemas {
+		if subschema == nil {
+			continue
+		}
+		if err := subschema.compilePatterns(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func schemaForType(t reflect.Type) *JSONSchema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &JSONSchema{AnyOf: []*JSONSchema{{Type: "string"}, {Type: "number"}}, timestamp: true}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice:
+		return &JSONSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Struct:
+		schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			presence, ok := field.Tag.Lookup("schema")
+			if !ok {
+				continue // Calculated fields are not part of the input
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+			property := schemaForType(field.Type)
+			if extra, ok := schemaFields[name]; ok {
+				property.Description = extra.Description
+				property.Pattern = extra.Pattern
+				property.MinLength = extra.MinLength
+				property.MinItems = extra.MinItems
+			}
+			schema.Properties[name] = property
+			if presence == "required" {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		return schema
+	}
+	return &JSONSchema{}
+}
+
+// SchemaError is a value that doesn't match the schema, located by a JSON
+// pointer such as /alerts/3/timestamp
+type SchemaError struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// validateFileSchema checks an input file against the input schema: every
+// entry of it if it is an archive, and every file read from it if it is a
+// directory
+func validateFileSchema(filename string, option LoadOptions) ([]SchemaError, error) {
+	option = mergeLoadOptions([]LoadOptions{option})
+	files := []string{filename}
+	if info, err := os.Stat(filename); err == nil && info.IsDir() {
+		if files, err = listInputFiles(filename, option); err != nil {
+			return nil, fmt.Errorf("error listing directory '%s': %v", filename, err)
+		}
+	}
+
+	schema := inputSchema(option.Severities)
+	if err := schema.compilePatterns(); err != nil {
+		return nil, err
+	}
+
+	var errs []SchemaError
+	for _, file := range files {
+		documents, err := readInputDocuments(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range documents {
+			var value interface{}
+			if err := json.Unmarshal(document.Data, &value); err != nil {
+				return nil, fmt.Errorf("error parsing JSON from '%s': %v", document.Name, err)
+			}
+
+			// Errors in archive entries and directory files are located by
+			// their name too
+			start := len(errs)
+			schema.validate(value, "", &errs)
+			if document.Name != filename {
+				name := strings.TrimLeft(strings.TrimPrefix(document.Name, filename), ":/"+string(filepath.Separator))
+				for i := start; i < len(errs); i++ {
+					errs[i].Pointer = name + ":" + errs[i].Pointer
+				}
+			}
+		}
+	}
+	return errs, nil
+}
+
+// validate appends an error for every part of value that doesn't match the
+// schema, which must have its patterns compiled. Properties are checked in
+// name order, so errors are stable.
+func (schema *JSONSchema) validate(value interface{}, pointer string, errs *[]SchemaError) {
+	fail := func(format string, args ...interface{}) {
+		location := pointer
+		if location == "" {
+			location = "/"
+		}
+		*errs = append(*errs, SchemaError{Pointer: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if schema.Type != "" && !matchesType(schema.Type, value) {
+		fail("expected %s, got %s", schema.Type, jsonType(value))
+		return
+	}
+	if len(schema.AnyOf) > 0 && !schema.matchesAnyOf(value) {
+		var types []string
+		for _, alternative := range schema.AnyOf {
+			types = append(types, alternative.Type)
+		}
+		fail("expected %s, got %s", strings.Join(types, " or "), jsonType(value))
+		return
+	}
+	if schema.timestamp {
+		if _, err := parseTimestamp(value); err != nil {
+			fail("%v", err)
+		}
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		if schema.MinLength != nil && len(v) < *schema.MinLength {
+			fail("must not be empty")
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(v) {
+			fail("'%s' does not match %s", v, schema.Pattern)
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			fail("expected at least %d items, got %d", *schema.MinItems, len(v))
+		}
+		if schema.Items != nil {
+			for i, item := range v {
+				schema.Items.validate(item, fmt.Sprintf("%s/%d", pointer, i), errs)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property '%s'", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			if property != nil {
+				property.validate(v[name], pointer+"/"+escapePointer(name), errs)
+			}
+		}
+	}
+}
+
+// matchesAnyOf reports whether value matches at least one of the
+// alternatives of the schema
+func (schema *JSONSchema) matchesAnyOf(value interface{}) bool {
+	for _, alternative := range schema.AnyOf {
+		var errs []SchemaError
+		if alternative.validate(value, "", &errs); len(errs) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(schemaType string, value interface{}) bool {
+	if schemaType == "integer" {
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return schemaType == jsonType(value)
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// escapePointer escapes a property name for a JSON pointer (RFC 6901)
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func showSchemaErrors(w io.Writer, filename string, errs []SchemaError) {
+	for _, err := range errs {
+		fmt.Fprintf(w, "❌ %s: %s: schema: %s\n", filename, err.Pointer, err.Message)
+	}
+}
+
+// checkSchema validates the input file against the input schema with
+// --schema, failing on any mismatch
+func checkSchema(config *Config) error {
+	if !config.Schema {
+		return nil
+	}
+
+	errs, err := validateFileSchema(config.InputFile, config.loadOptions())
+	if err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	showSchemaErrors(config.reportWriter(), config.InputFile, errs)
+	return fmt.Errorf("'%s' does not match the input schema: %d errors", config.InputFile, len(errs))
+}
+
+func runSchemaCommand(args []string) error {
+	var outputFile, severitiesFile string
+	fs := newCommandFlagSet("schema", "[-o <file>] [--severities <file>]",
+		"Prints the JSON Schema of input files, to validate exporters against.")
+	fs.StringVar(&outputFile, "o", "", "Output file (default stdout)")
+	fs.StringVar(&outputFile, "output", "", "Output file (default stdout)")
+	fs.StringVar(&severitiesFile, "severities", "", "JSON file with a custom severity ladder to accept")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	severities := defaultSeverities
+	if severitiesFile != "" {
+		var err error
+		if severities, err = loadSeverityModel(severitiesFile); err != nil {
+			return err
+		}
+	}
+
+	return writeOutput(&Config{OutputFile: outputFile}, func(w io.Writer) error {
+		return printJSON(w, inputSchema(severities))
+	})
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInputSchema(t *testing.T) {
+	schema := inputSchema(defaultSeverities)
+	if schema.Schema != schemaDialect || schema.Type != "object" {
+		t.Fatalf("Expected an object schema for %s, got %+v", schemaDialect, schema)
+	}
+
+	alert := schema.Properties["alerts"].Items
+	if alert == nil || alert.Type != "object" {
+		t.Fatalf("Expected alerts to be an array of objects, got %+v", schema.Properties["alerts"])
+	}
+	if _, ok := alert.Properties["priority"]; ok {
+		t.Error("Expected calculated fields to be left out of the schema")
+	}
+	if _, ok := alert.Properties["Priority"]; ok {
+		t.Error("Expected calculated fields to be left out of the schema")
+	}
+	if len(alert.Properties) != 11 {
+		t.Errorf("Expected 11 alert properties, got %d", len(alert.Properties))
+	}
+	if labels := alert.Properties["labels"]; labels.Type != "object" || labels.AdditionalProperties.Type != "string" {
+		t.Errorf("Expected labels to be a map of strings, got %+v", labels)
+	}
+	if timestamp := alert.Properties["timestamp"]; len(timestamp.AnyOf) != 2 || timestamp.AnyOf[0].Type != "string" || timestamp.AnyOf[1].Type != "number" {
+		t.Errorf("Expected timestamp to be a string or a number, got %+v", timestamp)
+	}
+	if value := alert.Properties["value"]; value.Type != "number" {
+		t.Errorf("Expected value to be a number, got %+v", value)
+	}
+
+	expected := []string{"id", "timestamp", "service", "severity", "metric", "value"}
+	if len(alert.Required) != len(expected) {
+		t.Fatalf("Expected required %v, got %v", expected, alert.Required)
+	}
+	for i, name := range expected {
+		if alert.Required[i] != name {
+			t.Errorf("Expected required %v, got %v", expected, alert.Required)
+		}
+	}
+
+	// The schema itself must be valid JSON
+	if _, err := json.Marshal(schema); err != nil {
+		t.Errorf("Unexpected error encoding the schema: %v", err)
+	}
+}
+
+func TestValidateFileSchema(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "alerts.json")
+	content := `{"alerts": [
+		{"id": "ALT-1", "timestamp": "2025-04-22T12:00:00Z", "service": "web", "severity": "Critical", "metric": "cpu", "value": 95, "extra": true},
+		{"id": "", "timestamp": true, "service": "web", "severity": "urgent", "metric": "cpu", "value": "high"},
+		{"id": "ALT-3", "timestamp": "yesterday", "severity": "info", "metric": "cpu", "value": 1}
+	]}`
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	errs, err := validateFileSchema(filename, LoadOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"/alerts/1/id",
+		"/alerts/1/severity",
+		"/alerts/1/timestamp",
+		"/alerts/1/value",
+		"/alerts/2",
+		"/alerts/2/timestamp",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d schema errors, got %d: %+v", len(expected), len(errs), errs)
+	}
+	for i, pointer := range expected {
+		if errs[i].Pointer != pointer {
+			t.Errorf("Expected error %d at %s, got %+v", i, pointer, errs[i])
+		}
+	}
+
+	if errs, _ := validateFileSchema("sample-alerts.json", LoadOptions{}); len(errs) != 0 {
+		t.Errorf("Expected the sample alerts to match the schema, got %+v", errs)
+	}
+}
+
+func TestValidateFileSchemaTimestamps(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "alerts.json")
+	content := `{"alerts": [
+		{"id": "ALT-1", "timestamp": 1718013600, "service": "web", "severity": "critical", "metric": "cpu", "value": 95},
+		{"id": "ALT-2", "timestamp": "1718013600000", "service": "web", "severity": "critical", "metric": "cpu", "value": 95},
+		{"id": "ALT-3", "timestamp": "2024-06-10T10:00:00+0300", "service": "web", "severity": "critical", "metric": "cpu", "value": 95},
+		{"id": "ALT-4", "timestamp": "2024-06-10 10:00:00", "service": "web", "severity": "critical", "metric": "cpu", "value": 95}
+	]}`
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	errs, err := validateFileSchema(filename, LoadOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Only the timestamp without a zone fails, as it does when loading
+	if len(errs) != 1 || errs[0].Pointer != "/alerts/3/timestamp" || !strings.Contains(errs[0].Message, "ambiguous") {
+		t.Errorf("Expected only the timestamp without a zone to fail, got %+v", errs)
+	}
+}
+
+func TestCompileSchemaPatterns(t *testing.T) {
+	schema := &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string", Pattern: "^(a|b)$"}}
+	if err := schema.compilePatterns(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var errs []SchemaError
+	schema.validate([]interface{}{"a", "c"}, "", &errs)
+	if len(errs) != 1 || errs[0].Pointer != "/1" {
+		t.Errorf("Expected only /1 not to match, got %+v", errs)
+	}
+
+	schema.Items.Pattern = "^(a|b$"
+	if err := schema.compilePatterns(); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestEscapePointer(t *testing.T) {
+	if escaped := escapePointer("a/b~c"); escaped != "a~1b~0c" {
+		t.Errorf("Expected a~1b~0c, got %s", escaped)
+	}
+}
+
+func TestRunSchemaCommand_Settings(t *testing.T) {
+	severitiesFile := createTestFile(t, testSeveritiesJSON)
+	defer os.Remove(severitiesFile)
+	settingsFile := createTestFile(t, "severities: "+severitiesFile+"\n")
+	defer os.Remove(settingsFile)
+	outputFile := filepath.Join(t.TempDir(), "schema.json")
+
+	if err := runSchemaCommand([]string{"--config", settingsFile, "-o", outputFile}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Expected schema file, got: %v", err)
+	}
+	var schema JSONSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Expected a JSON schema, got: %v", err)
+	}
+	expected := inputSchema(loadTestSeverities(t)).Properties["alerts"].Items.Properties["severity"].Pattern
+	if pattern := schema.Properties["alerts"].Items.Properties["severity"].Pattern; pattern != expected {
+		t.Errorf("Expected the severity ladder from the config file in the schema, got pattern %s", pattern)
+	}
+}
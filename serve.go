@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Server timeouts. Requests are small GETs; the write timeout leaves room to
+// load and prepare a large input file.
+const (
+	serveReadHeaderTimeout = 10 * time.Second
+	serveReadTimeout       = 30 * time.Second
+	serveWriteTimeout      = 2 * time.Minute
+)
+
+// newServeHandler serves prepared alerts as JSON. The input file is read on
+// every request, so a server keeps up with a file that is regularly replaced.
+func newServeHandler(config *Config) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	mux.HandleFunc("GET /alerts", func(w http.ResponseWriter, r *http.Request) {
+		if alerts, ok := loadServedAlerts(w, config); ok {
+			writeJSONResponse(w, http.StatusOK, alerts)
+		}
+	})
+
+	mux.HandleFunc("GET /top", func(w http.ResponseWriter, r *http.Request) {
+		n := config.Top
+		if value := r.URL.Query().Get("n"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				writeJSONError(w, http.StatusBadRequest, fmt.Errorf("n must be a positive number"))
+				return
+			}
+			n = parsed
+		}
+
+		alerts, ok := loadServedAlerts(w, config)
+		if !ok {
+			return
+		}
+		if len(alerts.Alerts) > n {
+			alerts.Alerts = alerts.Alerts[:n]
+		}
+		writeJSONResponse(w, http.StatusOK, alerts)
+	})
+
+	mux.HandleFunc("GET /groups", func(w http.ResponseWriter, r *http.Request) {
+		field := normalizeField(r.URL.Query().Get("by"))
+		if !isGroupableField(field) {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid groupby field '%s'. Valid fields: %s",
+				field, groupableFieldsHelp()))
+			return
+		}
+		if alerts, ok := loadServedAlerts(w, config); ok {
+			writeJSONResponse(w, http.StatusOK, alerts.Group(field))
+		}
+	})
+
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		if alerts, ok := loadServedAlerts(w, config); ok {
+			writeJSONResponse(w, http.StatusOK, buildStatsReport(*alerts, config))
+		}
+	})
+
+	return mux
+}
+
+// loadServedAlerts loads and prepares the input for a request, answering
+// with an error if that fails
+func loadServedAlerts(w http.ResponseWriter, config *Config) (*Alerts, bool) {
+	if err := checkSchema(config); err != nil {
+		writeJSONError(w, http.StatusUnprocessableEntity, err)
+		return nil, false
+	}
+	alerts, err := loadAlertsFromFile(config.InputFile, config.loadOptions())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if err := checkStrict(*alerts, config); err != nil {
+		writeJSONError(w, http.StatusUnprocessableEntity, err)
+		return nil, false
+	}
+	prepared := config.pipeline().Run(*alerts)
+	return &prepared, true
+}
+
+func writeJSONResponse(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSONResponse(w, status, map[string]string{"error": err.Error()})
+}
+
+func runServeCommand(args []string) error {
+	config := newConfig()
+	var addr string
+	fs := newCommandFlagSet("serve", "-i <input-file> [--addr :8080] [OPTIONS]",
+		"Serves alerts as JSON over HTTP: /alerts, /top?n=10, /groups?by=service, /stats and /healthz.")
+	registerInputFlags(fs, config)
+	fs.StringVar(&addr, "addr", ":8080", "Address to listen on")
+	fs.IntVar(&config.Top, "top", config.Top, "Default number of alerts for /top")
+	registerAnalysisFlags(fs, config)
+	registerValidationFlags(fs, config)
+	registerRankingFlags(fs, config)
+	fs.DurationVar(&config.TrendsWindow, "trends-window", config.TrendsWindow, "Window for trends in /stats")
+	if err := applySettings(fs, args); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if config.Top <= 0 {
+		return fmt.Errorf("top must be a positive number")
+	}
+	if err := config.validate(); err != nil {
+		return err
+	}
+	if err := config.load(); err != nil {
+		return err
+	}
+	config.reports = io.Discard
+
+	fmt.Printf("🌐 Serving %s on %s\n", config.InputFile, addr)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newServeHandler(config),
+		ReadHeaderTimeout: serveReadHeaderTimeout,
+		ReadTimeout:       serveReadTimeout,
+		WriteTimeout:      serveWriteTimeout,
+	}
+	return server.ListenAndServe()
+}
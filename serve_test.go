@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestServeHandler(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+
+	config := newConfig()
+	config.InputFile = testFile
+	config.reports = io.Discard
+	server := httptest.NewServer(newServeHandler(config))
+	defer server.Close()
+
+	get := func(path string, v interface{}) int {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if v != nil {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("GET %s: expected JSON, got: %v", path, err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	var top Alerts
+	if status := get("/top?n=1", &top); status != http.StatusOK || len(top.Alerts) != 1 || top.Alerts[0].ID != "ALT-001" {
+		t.Errorf("Unexpected /top response: %d %+v", status, top)
+	}
+
+	var groups map[string]Alerts
+	if status := get("/groups?by=service", &groups); status != http.StatusOK || len(groups) != 2 {
+		t.Errorf("Unexpected /groups response: %d %+v", status, groups)
+	}
+
+	var stats StatsReport
+	if status := get("/stats", &stats); status != http.StatusOK || stats.Summary.Total != 2 {
+		t.Errorf("Unexpected /stats response: %d %+v", status, stats)
+	}
+
+	if status := get("/groups?by=owner", nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid group field, got %d", status)
+	}
+	if status := get("/top?n=zero", nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid n, got %d", status)
+	}
+	if status := get("/alerts", nil); status != http.StatusOK {
+		t.Errorf("Expected 200 for /alerts, got %d", status)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix prefixes the environment variables that override settings,
+// e.g. ENC_ALERTBUDDY_LASTMINUTES for --lastminutes
+const envPrefix = "ENC_ALERTBUDDY_"
+
+// settingAliases are config file keys that name a flag differently
+var settingAliases = map[string]string{
+	"silences":    "maintenance",
+	"routing":     "routes",
+	"notifier":    "type",
+	"webhook-url": "webhook",
+	"recipients":  "to",
+}
+
+// Settings are the contents of a config file: flag values by flag name,
+// plus named profiles that override them
+type Settings struct {
+	Base     map[string]string
+	Profiles map[string]map[string]string
+}
+
+// settingSource is where an effective setting came from
+type settingSource struct {
+	Value  string
+	Source string
+}
+
+// defaultConfigPath returns the config file used without --config, or ""
+// when there is no user config directory
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, AppName, "config.yaml")
+}
+
+// shortFlags maps single-letter flags to the setting they are an alias of
+var shortFlags = map[string]string{"i": "input", "o": "output", "a": "show-all", "n": "top"}
+
+// registerSettingFlags defines every flag that can be set from a config file
+func registerSettingFlags(fs *flag.FlagSet, config *Config) {
+	registerInputFlags(fs, config)
+	registerOutputFlags(fs, config)
+	registerDisplayFlags(fs, config)
+	registerTimeFlags(fs, config)
+	registerAnalysisFlags(fs, config)
+	registerRankingFlags(fs, config)
+	registerValidationFlags(fs, config)
+	registerNotifierFlags(fs, config)
+	registerMailFlags(fs, config)
+}
+
+// knownSettings returns the flag names that can be set from a config file or
+// the environment. Single-letter aliases are left out.
+func knownSettings() map[string]bool {
+	fs := flag.NewFlagSet("settings", flag.ContinueOnError)
+	registerSettingFlags(fs, newConfig())
+
+	known := make(map[string]bool)
+	fs.VisitAll(func(f *flag.Flag) {
+		if len(f.Name) > 1 {
+			known[f.Name] = true
+		}
+	})
+	return known
+}
+
+func loadSettings(filename string) (*Settings, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file '%s': %v", filename, err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing config from '%s': %v", filename, err)
+	}
+
+	known := knownSettings()
+	settings := &Settings{Profiles: make(map[string]map[string]string)}
+	if profiles, ok := raw["profiles"]; ok {
+		delete(raw, "profiles")
+		byName, ok := profiles.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid config in '%s': profiles must be a mapping", filename)
+		}
+		for name, values := range byName {
+			profile, ok := values.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid config in '%s': profile '%s' must be a mapping", filename, name)
+			}
+			if settings.Profiles[name], err = normalizeSettings(profile, known); err != nil {
+				return nil, fmt.Errorf("invalid profile '%s' in '%s': %v", name, filename, err)
+			}
+		}
+	}
+
+	if settings.Base, err = normalizeSettings(raw, known); err != nil {
+		return nil, fmt.Errorf("invalid config in '%s': %v", filename, err)
+	}
+	return settings, nil
+}
+
+// normalizeSettings turns config file values into flag values by flag name.
+// A weights mapping sets the matching <name>-weight flags.
+func normalizeSettings(raw map[string]interface{}, known map[string]bool) (map[string]string, error) {
+	values := make(map[string]string)
+	for key, value := range raw {
+		if key == "weights" {
+			weights, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("weights must be a mapping")
+			}
+			for term, weight := range weights {
+				name := term + "-weight"
+				if !known[name] {
+					return nil, fmt.Errorf("unknown weight '%s'", term)
+				}
+				values[name] = fmt.Sprint(weight)
+			}
+			continue
+		}
+
+		if alias, ok := settingAliases[key]; ok {
+			key = alias
+		}
+		if !known[key] {
+			return nil, fmt.Errorf("unknown setting '%s'", key)
+		}
+		if _, ok := value.(map[string]interface{}); ok {
+			return nil, fmt.Errorf("setting '%s' must be a single value", key)
+		}
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+			continue
+		}
+		values[key] = fmt.Sprint(value)
+	}
+	return values, nil
+}
+
+// scanFlag returns the value of a string flag from unparsed arguments
+func scanFlag(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		trimmed := strings.TrimLeft(arg, "-")
+		if trimmed == arg {
+			continue
+		}
+		if trimmed == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+		if strings.HasPrefix(trimmed, name+"=") {
+			return strings.TrimPrefix(trimmed, name+"="), true
+		}
+	}
+	return "", false
+}
+
+// effectiveSettings merges the config file, the selected profile and the
+// environment, later sources winning. Command-line flags are applied on top
+// when the flag set is parsed. An explicit --config must exist; the default
+// config file is optional.
+func effectiveSettings(args []string) (map[string]settingSource, string, string, error) {
+	configFile, explicit := scanFlag(args, "config")
+	if !explicit {
+		configFile, explicit = os.LookupEnv(envPrefix + "CONFIG")
+	}
+	if !explicit {
+		configFile = defaultConfigPath()
+	}
+	profile, hasProfile := scanFlag(args, "profile")
+	if !hasProfile {
+		profile = os.Getenv(envPrefix + "PROFILE")
+	}
+
+	effective := make(map[string]settingSource)
+	settings := &Settings{}
+	if configFile != "" {
+		if _, err := os.Stat(configFile); err == nil || explicit {
+			loaded, err := loadSettings(configFile)
+			if err != nil {
+				return nil, "", "", err
+			}
+			settings = loaded
+		} else {
+			configFile = ""
+		}
+	}
+
+	for key, value := range settings.Base {
+		effective[key] = settingSource{Value: value, Source: "config"}
+	}
+	if profile != "" {
+		values, ok := settings.Profiles[profile]
+		if !ok {
+			return nil, "", "", fmt.Errorf("unknown profile '%s'", profile)
+		}
+		for key, value := range values {
+			effective[key] = settingSource{Value: value, Source: "profile " + profile}
+		}
+	}
+
+	for key := range knownSettings() {
+		variable := envPrefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		if value, ok := os.LookupEnv(variable); ok {
+			effective[key] = settingSource{Value: value, Source: "env " + variable}
+		}
+	}
+
+	return effective, configFile, profile, nil
+}
+
+// applySettings defines --config and --profile on a flag set and sets its
+// flags from the config file, profile and environment. Flags on the command
+// line still win, as they are parsed afterwards.
+func applySettings(fs *flag.FlagSet, args []string) error {
+	fs.String("config", "", "YAML config file (default "+defaultConfigPath()+")")
+	fs.String("profile", "", "Named profile from the config file")
+
+	effective, _, _, err := effectiveSettings(args)
+	if err != nil {
+		return err
+	}
+	for key, setting := range effective {
+		if fs.Lookup(key) == nil {
+			continue // Settings for other commands
+		}
+		if err := fs.Set(key, setting.Value); err != nil {
+			return fmt.Errorf("invalid value '%s' for '%s' from %s: %v", setting.Value, key, setting.Source, err)
+		}
+	}
+	return nil
+}
+
+func runConfigCommand(args []string) error {
+	if len(args) == 0 || args[0] != "show" {
+		return fmt.Errorf("unknown config command. Use '%s config show'", AppName)
+	}
+
+	fs := newCommandFlagSet("config show", "[--config <file>] [--profile <name>] [FLAGS]",
+		"Prints the effective configuration: defaults, overridden by the config file,\n"+
+			"the profile, ENC_ALERTBUDDY_* environment variables and flags, in that order.")
+	registerSettingFlags(fs, newConfig())
+	if err := applySettings(fs, args[1:]); err != nil {
+		return err
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	effective, configFile, profile, err := effectiveSettings(args[1:])
+	if err != nil {
+		return err
+	}
+
+	// Parse the flags again on their own to tell which were on the command line
+	commandLine := flag.NewFlagSet("config show", flag.ContinueOnError)
+	registerSettingFlags(commandLine, newConfig())
+	commandLine.String("config", "", "")
+	commandLine.String("profile", "", "")
+	commandLine.Parse(args[1:])
+	commandLine.Visit(func(f *flag.Flag) {
+		name := f.Name
+		if long, ok := shortFlags[name]; ok {
+			name = long
+		}
+		effective[name] = settingSource{Source: "flag"}
+	})
+
+	if configFile == "" {
+		configFile = "none"
+	}
+	if profile == "" {
+		profile = "none"
+	}
+	fmt.Printf("⚙️  Effective configuration (config: %s, profile: %s)\n", configFile, profile)
+	fmt.Println(strings.Repeat("-", 40))
+
+	var names []string
+	for name := range knownSettings() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		source := "default"
+		if setting, ok := effective[name]; ok {
+			source = setting.Source
+		}
+		fmt.Printf("  %-18s %-24s (%s)\n", name, fs.Lookup(name).Value.String(), source)
+	}
+	return nil
+}
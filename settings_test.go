@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const testSettingsContent = `input: alerts.json
+silences: windows.json
+lastminutes: 60
+history: [monday.json, tuesday.json]
+weights:
+  severity: 2
+profiles:
+  oncall:
+    lastminutes: 30
+    rank-by: critical
+`
+
+// TestMain keeps the tests away from the user's config file and
+// ENC_ALERTBUDDY_* variables, which every command applies
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "enc-alertbuddy-home-*")
+	if err != nil {
+		panic(err)
+	}
+	for _, variable := range []string{"HOME", "XDG_CONFIG_HOME", "AppData"} {
+		os.Setenv(variable, home)
+	}
+	for _, entry := range os.Environ() {
+		if name, _, _ := strings.Cut(entry, "="); strings.HasPrefix(name, envPrefix) {
+			os.Unsetenv(name)
+		}
+	}
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
+func TestLoadSettings(t *testing.T) {
+	testFile := createTestFile(t, testSettingsContent)
+	defer os.Remove(testFile)
+
+	settings, err := loadSettings(testFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := map[string]string{
+		"input":           "alerts.json",
+		"maintenance":     "windows.json",
+		"lastminutes":     "60",
+		"history":         "monday.json,tuesday.json",
+		"severity-weight": "2",
+	}
+	for key, value := range expected {
+		if settings.Base[key] != value {
+			t.Errorf("Expected %s = %s, got %q", key, value, settings.Base[key])
+		}
+	}
+	if settings.Profiles["oncall"]["rank-by"] != "critical" {
+		t.Errorf("Expected the oncall profile to be loaded, got %v", settings.Profiles)
+	}
+
+	invalid := map[string]string{
+		"colour: blue\n":                        "unknown setting 'colour'",
+		"weights:\n  luck: 3\n":                 "unknown weight 'luck'",
+		"profiles:\n  oncall:\n    colour: x\n": "invalid profile 'oncall'",
+		"input: [\n":                            "error parsing config",
+	}
+	for content, expected := range invalid {
+		invalidFile := createTestFile(t, content)
+		defer os.Remove(invalidFile)
+
+		_, err := loadSettings(invalidFile)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing '%s', got: %v", expected, err)
+		}
+	}
+}
+
+func TestParseFlags_SettingsPrecedence(t *testing.T) {
+	resetFlags()
+
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+	settingsFile := createTestFile(t, `input: `+testFile+`
+lastminutes: 60
+rank-top: 3
+groupby: service
+weights:
+  severity: 2
+profiles:
+  oncall:
+    lastminutes: 30
+    rank-by: critical
+`)
+	defer os.Remove(settingsFile)
+
+	t.Setenv("ENC_ALERTBUDDY_RANK_TOP", "7")
+	os.Args = []string{"enc-alertbuddy", "--config", settingsFile, "--profile=oncall", "--groupby=severity"}
+
+	config, err := parseFlags()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if config.InputFile != testFile {
+		t.Errorf("Expected input from the config file, got %s", config.InputFile)
+	}
+	if config.LastMinutes != 30 || config.RankBy != "critical" {
+		t.Errorf("Expected the profile to override the config file, got %d and %s", config.LastMinutes, config.RankBy)
+	}
+	if config.RankTop != 7 {
+		t.Errorf("Expected the environment to override the config file, got %d", config.RankTop)
+	}
+	if config.GroupBy != "severity" {
+		t.Errorf("Expected the flag to override the config file, got %s", config.GroupBy)
+	}
+	if config.weights.Severity != 2 || config.weights.Components != 2.0 {
+		t.Errorf("Expected severity weight from the config file, got %+v", config.weights)
+	}
+
+	resetFlags()
+	os.Args = []string{"enc-alertbuddy", "--config", settingsFile, "--profile=weekly"}
+	if _, err := parseFlags(); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+		t.Errorf("Expected error for unknown profile, got: %v", err)
+	}
+
+	resetFlags()
+	os.Args = []string{"enc-alertbuddy", "--config", "missing.yaml", "-i", testFile}
+	if _, err := parseFlags(); err == nil {
+		t.Error("Expected error for a missing explicit config file, got nil")
+	}
+}
+
+func TestScanFlag(t *testing.T) {
+	args := []string{"-i", "alerts.json", "--profile=oncall", "-config", "c.yaml", "--", "--config=ignored"}
+	if value, ok := scanFlag(args, "profile"); !ok || value != "oncall" {
+		t.Errorf("Expected profile oncall, got %q", value)
+	}
+	if value, ok := scanFlag(args, "config"); !ok || value != "c.yaml" {
+		t.Errorf("Expected config c.yaml, got %q", value)
+	}
+	if _, ok := scanFlag(args, "output"); ok {
+		t.Error("Expected no output flag")
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SeverityLevel is one step of a severity ladder
+type SeverityLevel struct {
+	Name    string   `json:"name"`
+	Score   float64  `json:"score"`   // Severity term of the priority
+	Aliases []string `json:"aliases"` // Other names producers use for the level, e.g. "crit" or "P1"
+}
+
+// SeverityModel is an ordered ladder of severities, highest first. Names and
+// aliases are matched ignoring case.
+type SeverityModel struct {
+	Levels  []SeverityLevel `json:"levels"`
+	Unknown string          `json:"unknown"` // Level that severities not on the ladder are scored as; defaults to the lowest
+
+	ranks   map[string]int // Lowercased name or alias to position on the ladder
+	unknown int
+}
+
+// DefaultSeverityModel returns the built-in ladder: critical, warning, info
+func DefaultSeverityModel() *SeverityModel {
+	model := &SeverityModel{
+		Levels: []SeverityLevel{
+			{Name: "critical", Score: 10, Aliases: []string{"crit"}},
+			{Name: "warning", Score: 5, Aliases: []string{"warn"}},
+			{Name: "info", Score: 1},
+		},
+		Unknown: "info",
+	}
+	model.compile()
+	return model
+}
+
+// defaultSeverities scores alerts that were not read through loadAlertsFromFile
+var defaultSeverities = DefaultSeverityModel()
+
+func loadSeverityModel(filename string) (*SeverityModel, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading severities file '%s': %v", filename, err)
+	}
+
+	var model SeverityModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("error parsing severities from '%s': %v", filename, err)
+	}
+	if err := model.compile(); err != nil {
+		return nil, fmt.Errorf("invalid severities in '%s': %v", filename, err)
+	}
+	return &model, nil
+}
+
+// compile validates the ladder and indexes its names and aliases
+func (model *SeverityModel) compile() error {
+	if len(model.Levels) == 0 {
+		return fmt.Errorf("no severity levels")
+	}
+
+	model.ranks = make(map[string]int)
+	for rank, level := range model.Levels {
+		if level.Name == "" {
+			return fmt.Errorf("severity level %d has no name", rank+1)
+		}
+		if level.Score <= 0 {
+			return fmt.Errorf("severity '%s' needs a positive score", level.Name)
+		}
+		for _, name := range append([]string{level.Name}, level.Aliases...) {
+			key := strings.ToLower(name)
+			if _, exists := model.ranks[key]; exists {
+				return fmt.Errorf("severity '%s' is defined twice", name)
+			}
+			model.ranks[key] = rank
+		}
+	}
+
+	model.unknown = len(model.Levels) - 1
+	if model.Unknown != "" {
+		rank, ok := model.Rank(model.Unknown)
+		if !ok {
+			return fmt.Errorf("unknown severity level '%s'", model.Unknown)
+		}
+		model.unknown = rank
+	}
+	return nil
+}
+
+// Rank returns the position of a severity on the ladder, 0 being the highest
+func (model *SeverityModel) Rank(severity string) (int, bool) {
+	rank, ok := model.ranks[strings.ToLower(strings.TrimSpace(severity))]
+	return rank, ok
+}
+
+// Level returns the level of a severity. Severities not on the ladder get the
+// unknown level.
+func (model *SeverityModel) Level(severity string) SeverityLevel {
+	if rank, ok := model.Rank(severity); ok {
+		return model.Levels[rank]
+	}
+	return model.Levels[model.unknown]
+}
+
+// Normalize returns the ladder name of a severity or alias. Severities not on
+// the ladder are returned as they are, so validation can report them.
+func (model *SeverityModel) Normalize(severity string) string {
+	if rank, ok := model.Rank(severity); ok {
+		return model.Levels[rank].Name
+	}
+	return severity
+}
+
+// AtLeast reports whether a severity is at or above the given level
+func (model *SeverityModel) AtLeast(severity, minimum string) bool {
+	rank, ok := model.Rank(severity)
+	if !ok {
+		rank = model.unknown
+	}
+	minimumRank, _ := model.Rank(minimum)
+	return rank <= minimumRank
+}
+
+// Names returns the names of the levels, highest first
+func (model *SeverityModel) Names() []string {
+	names := make([]string, len(model.Levels))
+	for i, level := range model.Levels {
+		names[i] = level.Name
+	}
+	return names
+}
+
+// NormalizeSeverities sets every alert's severity to its ladder name and
+// records its score for the priority
+func (alerts *Alerts) NormalizeSeverities(model *SeverityModel) {
+	for i := range alerts.Alerts {
+		alert := &alerts.Alerts[i]
+		alert.Severity = model.Normalize(alert.Severity)
+		alert.severityScore = model.Level(alert.Severity).Score
+	}
+}
+
+// FilterByMinSeverity keeps alerts at or above the given severity
+func (a Alerts) FilterByMinSeverity(model *SeverityModel, minimum string) Alerts {
+	var filtered []Alert
+
+	for _, alert := range a.Alerts {
+		if model.AtLeast(alert.Severity, minimum) {
+			filtered = append(filtered, alert)
+		}
+	}
+
+	return Alerts{
+		Alerts: filtered,
+	}
+}
+
+// severityBreakdown orders severity counts along the ladder, followed by
+// severities that are not on it in name order
+func severityBreakdown(model *SeverityModel, counts map[string]int) []countEntry {
+	var entries []countEntry
+	for _, name := range model.Names() {
+		if count, exists := counts[name]; exists {
+			entries = append(entries, countEntry{Name: name, Count: count})
+		}
+	}
+
+	var others []string
+	for name := range counts {
+		if _, known := model.Rank(name); !known || model.Normalize(name) != name {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	for _, name := range others {
+		entries = append(entries, countEntry{Name: name, Count: counts[name]})
+	}
+	return entries
+}
+
+// casePattern matches any of the names ignoring case, for JSON Schema
+// patterns which have no case-insensitive flag
+func casePattern(names []string) string {
+	alternatives := make([]string, len(names))
+	for i, name := range names {
+		var pattern strings.Builder
+		for _, r := range name {
+			lower, upper := strings.ToLower(string(r)), strings.ToUpper(string(r))
+			if lower == upper {
+				pattern.WriteString(regexp.QuoteMeta(string(r)))
+				continue
+			}
+			pattern.WriteString("[" + lower + upper + "]")
+		}
+		alternatives[i] = pattern.String()
+	}
+	return "^(?:" + strings.Join(alternatives, "|") + ")$"
+}
+
+// allNames returns the names and aliases of the ladder
+func (model *SeverityModel) allNames() []string {
+	var names []string
+	for _, level := range model.Levels {
+		names = append(names, level.Name)
+		names = append(names, level.Aliases...)
+	}
+	return names
+}
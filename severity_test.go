@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+const testSeveritiesJSON = `{
+	"levels": [
+		{"name": "emergency", "score": 15, "aliases": ["emerg", "P0"]},
+		{"name": "critical", "score": 10, "aliases": ["crit", "P1"]},
+		{"name": "error", "score": 7, "aliases": ["err"]},
+		{"name": "warning", "score": 5, "aliases": ["warn"]},
+		{"name": "notice", "score": 3},
+		{"name": "info", "score": 1},
+		{"name": "debug", "score": 0.5}
+	],
+	"unknown": "notice"
+}`
+
+func loadTestSeverities(t *testing.T) *SeverityModel {
+	testFile := createTestFile(t, testSeveritiesJSON)
+	defer os.Remove(testFile)
+
+	model, err := loadSeverityModel(testFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return model
+}
+
+func TestSeverityModel(t *testing.T) {
+	model := loadTestSeverities(t)
+
+	tests := []struct {
+		severity   string
+		normalized string
+		score      float64
+		known      bool
+	}{
+		{"Critical", "critical", 10, true},
+		{"P0", "emergency", 15, true},
+		{" ERR ", "error", 7, true},
+		{"debug", "debug", 0.5, true},
+		{"urgent", "urgent", 3, false}, // Scored as the unknown level
+	}
+	for _, test := range tests {
+		if normalized := model.Normalize(test.severity); normalized != test.normalized {
+			t.Errorf("Expected %q to normalize to %s, got %s", test.severity, test.normalized, normalized)
+		}
+		if score := model.Level(test.severity).Score; score != test.score {
+			t.Errorf("Expected %q to score %.1f, got %.1f", test.severity, test.score, score)
+		}
+		if _, known := model.Rank(test.severity); known != test.known {
+			t.Errorf("Expected %q known=%v", test.severity, test.known)
+		}
+	}
+
+	if !model.AtLeast("emergency", "error") || !model.AtLeast("err", "error") || model.AtLeast("warning", "error") {
+		t.Error("Expected emergency and error, but not warning, to be at least error")
+	}
+	if model.AtLeast("urgent", "warning") || !model.AtLeast("urgent", "notice") {
+		t.Error("Expected unknown severities to rank as notice")
+	}
+}
+
+func TestSeverityModelErrors(t *testing.T) {
+	invalid := []SeverityModel{
+		{},
+		{Levels: []SeverityLevel{{Name: "high", Score: 0}}},
+		{Levels: []SeverityLevel{{Name: "high", Score: 2}, {Name: "low", Score: 1, Aliases: []string{"HIGH"}}}},
+		{Levels: []SeverityLevel{{Name: "high", Score: 2}}, Unknown: "medium"},
+	}
+	for _, model := range invalid {
+		if err := model.compile(); err == nil {
+			t.Errorf("Expected an error for %+v", model)
+		}
+	}
+}
+
+func TestNormalizeSeveritiesOnLoad(t *testing.T) {
+	model := loadTestSeverities(t)
+	content := `{"alerts": [
+		{"id": "ALT-1", "service": "web", "component": "a", "severity": "P0", "value": 1, "threshold": 1},
+		{"id": "ALT-2", "service": "web", "component": "b", "severity": "Critical", "value": 1, "threshold": 1},
+		{"id": "ALT-3", "service": "db", "component": "c", "severity": "warn", "value": 1, "threshold": 1},
+		{"id": "ALT-4", "service": "db", "component": "d", "severity": "critical", "value": 1, "threshold": 1}
+	]}`
+	testFile := createTestFile(t, content)
+	defer os.Remove(testFile)
+
+	alerts, err := loadAlertsFromFile(testFile, LoadOptions{Severities: model})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	alerts.CalculateAllPriorities()
+
+	// Severity score plus 2 for each of the 2 components of the service
+	expected := map[string]float64{"ALT-1": 19, "ALT-2": 14, "ALT-3": 9, "ALT-4": 14}
+	for _, alert := range alerts.Alerts {
+		if alert.Priority != expected[alert.ID] {
+			t.Errorf("Expected %s to have priority %.2f, got %.2f", alert.ID, expected[alert.ID], alert.Priority)
+		}
+	}
+
+	stats := summarizeAlerts(*alerts)
+	if stats.SeverityCounts["critical"] != 2 || stats.SeverityCounts["Critical"] != 0 {
+		t.Errorf("Expected Critical and critical to be counted together, got %v", stats.SeverityCounts)
+	}
+	breakdown := severityBreakdown(model, stats.SeverityCounts)
+	if len(breakdown) != 3 || breakdown[0].Name != "emergency" || breakdown[1].Name != "critical" || breakdown[2].Name != "warning" {
+		t.Errorf("Expected the breakdown in ladder order, got %v", breakdown)
+	}
+
+	filtered := alerts.FilterByMinSeverity(model, "critical")
+	if len(filtered.Alerts) != 3 {
+		t.Errorf("Expected 3 alerts at or above critical, got %d", len(filtered.Alerts))
+	}
+
+	grouped := alerts.Group("severity")
+	if len(grouped) != 3 || len(grouped["critical"].Alerts) != 2 {
+		t.Errorf("Expected 3 severity groups with 2 critical alerts, got %v", grouped)
+	}
+}
+
+func TestSeverityBreakdownUnknown(t *testing.T) {
+	breakdown := severityBreakdown(defaultSeverities, map[string]int{"info": 1, "urgent": 2, "critical": 3, "Page": 1})
+	expected := []string{"critical", "info", "Page", "urgent"}
+	if len(breakdown) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, breakdown)
+	}
+	for i, name := range expected {
+		if breakdown[i].Name != name {
+			t.Errorf("Expected %v, got %v", expected, breakdown)
+		}
+	}
+}
+
+func TestCasePattern(t *testing.T) {
+	pattern := regexp.MustCompile(casePattern([]string{"critical", "P1", "a.b"}))
+	for _, value := range []string{"critical", "CRITICAL", "Critical", "p1", "A.B"} {
+		if !pattern.MatchString(value) {
+			t.Errorf("Expected %q to match", value)
+		}
+	}
+	for _, value := range []string{"criticals", "P2", "axb", ""} {
+		if pattern.MatchString(value) {
+			t.Errorf("Expected %q not to match", value)
+		}
+	}
+}
+
+func TestFilterBySeverityIgnoresCase(t *testing.T) {
+	alerts := Alerts{Alerts: []Alert{{ID: "ALT-1", Severity: "Critical"}, {ID: "ALT-2", Severity: "crit"}, {ID: "ALT-3", Severity: "warning"}}}
+	if filtered := alerts.FilterBySeverity(defaultSeverities, "critical"); len(filtered.Alerts) != 2 {
+		t.Errorf("Expected 2 critical alerts, got %d", len(filtered.Alerts))
+	}
+
+	// Aliases come from the ladder the alerts were loaded with
+	custom := Alerts{Alerts: []Alert{{ID: "ALT-1", Severity: "P0"}, {ID: "ALT-2", Severity: "emerg"}, {ID: "ALT-3", Severity: "P1"}}}
+	if filtered := custom.FilterBySeverity(loadTestSeverities(t), "emergency"); len(filtered.Alerts) != 2 {
+		t.Errorf("Expected 2 emergency alerts, got %d", len(filtered.Alerts))
+	}
+}
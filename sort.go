@@ -0,0 +1,241 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sortFields are the fields alerts can be sorted by, besides labels and
+// annotations, with their default direction: scores highest first, times
+// and names in ascending order
+var sortFields = map[string]bool{
+	"priority": true, "severity": true, "value": true, "threshold": true, "deviation": true, "anomaly": true,
+	"timestamp": false, "id": false, "service": false, "component": false, "metric": false, "team": false,
+}
+
+// defaultSortSpec is the order alerts are shown in without --sort
+const defaultSortSpec = "priority:desc"
+
+// SortKey is one key of a multi-key sort
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// parseSortKeys parses keys such as priority:desc,timestamp:asc. A key
+// without a direction uses the field's default direction.
+func parseSortKeys(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, item := range splitList(spec) {
+		field, direction, hasDirection := strings.Cut(item, ":")
+		field = normalizeField(strings.TrimSpace(field))
+
+		descending, known := sortFields[field]
+		if _, _, isLabel := labelField(Alert{}, field); !known && !isLabel {
+			return nil, fmt.Errorf("invalid sort field '%s'. Valid fields: %s", field, sortFieldsHelp())
+		}
+		if hasDirection {
+			switch strings.ToLower(strings.TrimSpace(direction)) {
+			case "asc":
+				descending = false
+			case "desc":
+				descending = true
+			default:
+				return nil, fmt.Errorf("invalid sort direction '%s' for '%s'. Use asc or desc", direction, field)
+			}
+		}
+		keys = append(keys, SortKey{Field: field, Descending: descending})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no sort keys in '%s'", spec)
+	}
+	return keys, nil
+}
+
+// sortFieldsHelp lists the valid sort fields for help and error messages
+func sortFieldsHelp() string {
+	fields := make([]string, 0, len(sortFields))
+	for field := range sortFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ", ") + ", labels.<name>, annotations.<name>"
+}
+
+// alertOrder compares alerts by sort keys. Severities are compared by their
+// score on the ladder.
+type alertOrder struct {
+	keys       []SortKey
+	severities *SeverityModel
+}
+
+func newAlertOrder(keys []SortKey, severities *SeverityModel) alertOrder {
+	if keys == nil {
+		keys, _ = parseSortKeys(defaultSortSpec)
+	}
+	if severities == nil {
+		severities = defaultSeverities
+	}
+	return alertOrder{keys: keys, severities: severities}
+}
+
+// compare returns a negative number if a comes before b, a positive one if
+// it comes after and 0 if they tie on every key. Alerts without a label or
+// annotation sort after the ones with it in either direction.
+func (order alertOrder) compare(a, b Alert) int {
+	for _, key := range order.keys {
+		if result := compareMissing(a, b, key.Field); result != 0 {
+			return result
+		}
+		result := order.compareField(a, b, key.Field)
+		if key.Descending {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func (order alertOrder) compareField(a, b Alert, field string) int {
+	switch field {
+	case "priority":
+		return compareNumbers(a.Priority, b.Priority)
+	case "severity":
+		return compareNumbers(order.severities.Level(a.Severity).Score, order.severities.Level(b.Severity).Score)
+	case "value":
+		return compareNumbers(a.Value, b.Value)
+	case "threshold":
+		return compareNumbers(a.Threshold, b.Threshold)
+	case "deviation":
+		return compareNumbers(calculateDeviationPercentage(a.Value, a.Threshold), calculateDeviationPercentage(b.Value, b.Threshold))
+	case "anomaly":
+		return compareNumbers(a.AnomalyScore, b.AnomalyScore)
+	case "timestamp":
+		return a.Timestamp.Compare(b.Timestamp)
+	case "id":
+		return strings.Compare(a.ID, b.ID)
+	case "service":
+		return strings.Compare(a.Service, b.Service)
+	case "component":
+		return strings.Compare(a.Component, b.Component)
+	case "metric":
+		return strings.Compare(a.Metric, b.Metric)
+	case "team":
+		return strings.Compare(a.Team, b.Team)
+	}
+
+	aValues, name, _ := labelField(a, field)
+	bValues, _, _ := labelField(b, field)
+	return strings.Compare(aValues[name], bValues[name])
+}
+
+// compareMissing puts an alert that has a label or annotation before one
+// that doesn't
+func compareMissing(a, b Alert, field string) int {
+	aValues, name, isLabel := labelField(a, field)
+	if !isLabel {
+		return 0
+	}
+	bValues, _, _ := labelField(b, field)
+	_, aFound := aValues[name]
+	_, bFound := bValues[name]
+	switch {
+	case aFound && !bFound:
+		return -1
+	case !aFound && bFound:
+		return 1
+	}
+	return 0
+}
+
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// SortBy sorts alerts by sort keys, priority first if there are none. The
+// sort is stable, so alerts that tie keep their input order.
+func (alerts *Alerts) SortBy(keys []SortKey, severities *SeverityModel) {
+	order := newAlertOrder(keys, severities)
+	sort.SliceStable(alerts.Alerts, func(i, j int) bool {
+		return order.compare(alerts.Alerts[i], alerts.Alerts[j]) < 0
+	})
+}
+
+// SortTop moves the first n alerts in sort order to the front, in order,
+// and leaves the others after them in input order. It keeps a heap of n
+// alerts instead of sorting all of them. n <= 0 sorts all alerts.
+func (alerts *Alerts) SortTop(n int, keys []SortKey, severities *SeverityModel) {
+	if n <= 0 || n >= len(alerts.Alerts) {
+		alerts.SortBy(keys, severities)
+		return
+	}
+
+	selection := &topSelection{order: newAlertOrder(keys, severities), alerts: alerts.Alerts}
+	for i := range alerts.Alerts {
+		if selection.Len() < n {
+			heap.Push(selection, i)
+		} else if selection.before(i, selection.indexes[0]) {
+			selection.indexes[0] = i
+			heap.Fix(selection, 0)
+		}
+	}
+
+	// Pop the worst of the selection first, filling the top from the back
+	top := make([]Alert, n)
+	selected := make(map[int]bool, n)
+	for i := n - 1; i >= 0; i-- {
+		index := heap.Pop(selection).(int)
+		top[i] = alerts.Alerts[index]
+		selected[index] = true
+	}
+	rest := make([]Alert, 0, len(alerts.Alerts)-n)
+	for i, alert := range alerts.Alerts {
+		if !selected[i] {
+			rest = append(rest, alert)
+		}
+	}
+	alerts.Alerts = append(top, rest...)
+}
+
+// topSelection is a heap of alert indexes whose root is the alert that comes
+// last in sort order, so it is the one to replace
+type topSelection struct {
+	order   alertOrder
+	alerts  []Alert
+	indexes []int
+}
+
+// before reports whether the alert at index i comes before the one at j.
+// Ties go to the earlier alert, as in a stable sort.
+func (selection *topSelection) before(i, j int) bool {
+	if result := selection.order.compare(selection.alerts[i], selection.alerts[j]); result != 0 {
+		return result < 0
+	}
+	return i < j
+}
+
+func (selection *topSelection) Len() int { return len(selection.indexes) }
+func (selection *topSelection) Less(i, j int) bool {
+	return selection.before(selection.indexes[j], selection.indexes[i])
+}
+func (selection *topSelection) Swap(i, j int) {
+	selection.indexes[i], selection.indexes[j] = selection.indexes[j], selection.indexes[i]
+}
+func (selection *topSelection) Push(x interface{}) {
+	selection.indexes = append(selection.indexes, x.(int))
+}
+func (selection *topSelection) Pop() interface{} {
+	last := selection.indexes[len(selection.indexes)-1]
+	selection.indexes = selection.indexes[:len(selection.indexes)-1]
+	return last
+}
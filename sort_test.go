@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	keys, err := parseSortKeys("Severity, timestamp, priority:asc, labels.Region:desc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []SortKey{{"severity", true}, {"timestamp", false}, {"priority", false}, {"labels.Region", true}}
+	if fmt.Sprint(keys) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, keys)
+	}
+
+	for _, spec := range []string{"", "colour", "priority:up", "labels."} {
+		if _, err := parseSortKeys(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestSortByKeys(t *testing.T) {
+	base := time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC)
+	alerts := Alerts{Alerts: []Alert{
+		{ID: "ALT-1", Severity: "info", Priority: 50, Timestamp: base.Add(3 * time.Minute)},
+		{ID: "ALT-2", Severity: "critical", Priority: 90, Timestamp: base.Add(2 * time.Minute), Labels: map[string]string{"region": "eu"}},
+		{ID: "ALT-3", Severity: "critical", Priority: 90, Timestamp: base.Add(1 * time.Minute)},
+		{ID: "ALT-4", Severity: "warning", Priority: 90, Timestamp: base.Add(1 * time.Minute), Labels: map[string]string{"region": "us"}},
+	}}
+
+	for spec, expected := range map[string]string{
+		"priority:desc":              "ALT-2 ALT-3 ALT-4 ALT-1",
+		"severity,timestamp:asc":     "ALT-3 ALT-2 ALT-4 ALT-1",
+		"timestamp,severity:asc":     "ALT-4 ALT-3 ALT-2 ALT-1",
+		"labels.region:desc,id:desc": "ALT-4 ALT-2 ALT-3 ALT-1",
+	} {
+		keys, err := parseSortKeys(spec)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", spec, err)
+		}
+		sorted := Alerts{Alerts: append([]Alert(nil), alerts.Alerts...)}
+		sorted.SortBy(keys, nil)
+		if ids := alertIDs(sorted); ids != expected {
+			t.Errorf("Expected %s to sort as %s, got %s", spec, expected, ids)
+		}
+	}
+}
+
+func TestSortTopMatchesSort(t *testing.T) {
+	alerts := generateAlerts(5000)
+	alerts.CalculateAllPriorities()
+	keys, _ := parseSortKeys("severity,value:asc")
+
+	for _, n := range []int{1, 10, 4999, 5000, 0} {
+		expected := Alerts{Alerts: append([]Alert(nil), alerts.Alerts...)}
+		expected.SortBy(keys, nil)
+		top := Alerts{Alerts: append([]Alert(nil), alerts.Alerts...)}
+		top.SortTop(n, keys, nil)
+
+		shown := n
+		if n == 0 {
+			shown = len(alerts.Alerts)
+		}
+		for i := 0; i < shown; i++ {
+			if top.Alerts[i].ID != expected.Alerts[i].ID {
+				t.Fatalf("Expected alert %d of the top %d to be %s, got %s", i, n, expected.Alerts[i].ID, top.Alerts[i].ID)
+			}
+		}
+		if len(top.Alerts) != len(alerts.Alerts) {
+			t.Fatalf("Expected SortTop to keep all %d alerts, got %d", len(alerts.Alerts), len(top.Alerts))
+		}
+	}
+
+	// The alerts after the top keep their input order
+	top := Alerts{Alerts: append([]Alert(nil), alerts.Alerts...)}
+	top.SortTop(10, keys, nil)
+	for i := 11; i < len(top.Alerts); i++ {
+		if top.Alerts[i].ID < top.Alerts[i-1].ID {
+			t.Fatalf("Expected the rest in input order, got %s after %s", top.Alerts[i].ID, top.Alerts[i-1].ID)
+		}
+	}
+}
+
+func TestTopAndSortFlags(t *testing.T) {
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+
+	config := newConfig()
+	config.InputFile, config.Top = testFile, -1
+	if err := config.validate(); err == nil {
+		t.Error("Expected an error for a negative top")
+	}
+	config.Top, config.Sort = 0, "priority:sideways"
+	if err := config.validate(); err == nil {
+		t.Error("Expected an error for an invalid sort direction")
+	}
+	config.Sort = "timestamp"
+	if err := config.validate(); err != nil || config.sortKeys[0] != (SortKey{"timestamp", false}) {
+		t.Errorf("Expected timestamp ascending, got %v, %v", config.sortKeys, err)
+	}
+}
+
+func alertIDs(alerts Alerts) string {
+	ids := ""
+	for i, alert := range alerts.Alerts {
+		if i > 0 {
+			ids += " "
+		}
+		ids += alert.ID
+	}
+	return ids
+}
+
+func BenchmarkSortTop(b *testing.B) {
+	alerts := generateAlerts(100000)
+	alerts.CalculateAllPriorities()
+	b.Run("heap/10", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			top := Alerts{Alerts: append([]Alert(nil), alerts.Alerts...)}
+			top.SortTop(10, nil, nil)
+		}
+	})
+	b.Run("full-sort", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sorted := Alerts{Alerts: append([]Alert(nil), alerts.Alerts...)}
+			sorted.SortBy(nil, nil)
+		}
+	})
+}
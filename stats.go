@@ -0,0 +1,54 @@
+package main
+
+import "sort"
+
+// SummaryStats holds alert counts and the average priority of a collection
+type SummaryStats struct {
+	Total           int            `json:"total"`
+	SeverityCounts  map[string]int `json:"severity_counts"`
+	ServiceCounts   map[string]int `json:"service_counts"`
+	AveragePriority float64        `json:"average_priority"`
+}
+
+// summarizeAlerts counts alerts by severity and service
+func summarizeAlerts(alerts Alerts) SummaryStats {
+	stats := SummaryStats{
+		Total:          len(alerts.Alerts),
+		SeverityCounts: make(map[string]int),
+		ServiceCounts:  make(map[string]int),
+	}
+
+	var totalPriority float64
+	for _, alert := range alerts.Alerts {
+		stats.SeverityCounts[alert.Severity]++
+		stats.ServiceCounts[alert.Service]++
+		totalPriority += alert.Priority
+	}
+
+	if stats.Total > 0 {
+		stats.AveragePriority = totalPriority / float64(stats.Total)
+	}
+
+	return stats
+}
+
+// countEntry is a name with a count, used for sorted breakdowns
+type countEntry struct {
+	Name  string
+	Count int
+}
+
+// sortedCounts orders a count map by count (highest first), then by name
+func sortedCounts(counts map[string]int) []countEntry {
+	entries := make([]countEntry, 0, len(counts))
+	for name, count := range counts {
+		entries = append(entries, countEntry{Name: name, Count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// displayLayout is how alert times are rendered, with the zone abbreviation
+const displayLayout = "2006-01-02 15:04:05 MST"
+
+// timestampLayouts are the timestamp formats read from input, besides epoch
+// numbers. All of them carry a zone: "Z" or an offset with or without a colon.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999 Z07:00",
+	"2006-01-02 15:04:05.999999999 Z0700",
+	time.RFC1123Z,
+}
+
+// localLayouts are formats without a zone. Such timestamps are refused, as
+// they read differently depending on where the producer ran.
+var localLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTimestamp reads a timestamp in any of the accepted formats: RFC 3339,
+// offsets without a colon, a space instead of the T, RFC 1123 with a numeric
+// zone, and epoch seconds or milliseconds as numbers or numeric strings
+func parseTimestamp(value interface{}) (time.Time, error) {
+	if epoch, err := toFloat(value); err == nil {
+		return epochTime(epoch), nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("'%v' is not a timestamp", value)
+	}
+	text = strings.TrimSpace(text)
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range localLayouts {
+		if _, err := time.Parse(layout, text); err == nil {
+			return time.Time{}, fmt.Errorf("timestamp '%s' is ambiguous: it has no timezone. Add Z or an offset such as +03:00", text)
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp '%s'. Use RFC 3339, e.g. 2025-04-22T12:00:00Z, or epoch seconds", text)
+}
+
+// epochMsThreshold tells epoch milliseconds from seconds when the format is
+// not given: as seconds, it would be after the year 5000
+const epochMsThreshold = 1e11
+
+// epochTime reads epoch seconds, or milliseconds if the number is too large
+// to be seconds
+func epochTime(epoch float64) time.Time {
+	if math.Abs(epoch) >= epochMsThreshold {
+		return time.UnixMilli(int64(epoch)).UTC()
+	}
+	sec, frac := math.Modf(epoch)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
+
+// unmarshalTimestamp reads the raw JSON value of a timestamp field
+func unmarshalTimestamp(raw json.RawMessage) (time.Time, error) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return time.Time{}, err
+	}
+	if value == nil {
+		return time.Time{}, nil
+	}
+	return parseTimestamp(value)
+}
+
+// parseTimezone reads a --tz value: an IANA zone such as Europe/Helsinki,
+// "local" or "UTC". An empty value keeps the zones of the input.
+func parseTimezone(name string) (*time.Location, error) {
+	switch strings.ToLower(name) {
+	case "":
+		return nil, nil
+	case "local":
+		return time.Local, nil
+	case "utc":
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s'. Use an IANA name such as Europe/Helsinki, or local", name)
+	}
+	return location, nil
+}
+
+// TimeDisplay is how alert times are rendered
+type TimeDisplay struct {
+	Location *time.Location   // Zone to render times in; the zone they were read with if nil
+	Relative bool             // Add how long ago the time was, e.g. "12m ago"
+	Now      func() time.Time // Clock for relative times; time.Now if nil
+}
+
+// Format renders a time in the display zone, followed by the relative time
+// if enabled
+func (display TimeDisplay) Format(t time.Time) string {
+	if display.Location != nil {
+		t = t.In(display.Location)
+	}
+	text := t.Format(displayLayout)
+	if display.Relative {
+		now := time.Now
+		if display.Now != nil {
+			now = display.Now
+		}
+		text += " (" + relativeTime(t, now()) + ")"
+	}
+	return text
+}
+
+// relativeTime renders the distance between two times in the largest whole
+// unit, e.g. "12m ago", "3h ago" or "in 2d"
+func relativeTime(t, now time.Time) string {
+	distance := now.Sub(t)
+	future := distance < 0
+	if future {
+		distance = -distance
+	}
+
+	var amount string
+	switch {
+	case distance < time.Minute:
+		return "just now"
+	case distance < time.Hour:
+		amount = strconv.Itoa(int(distance/time.Minute)) + "m"
+	case distance < 24*time.Hour:
+		amount = strconv.Itoa(int(distance/time.Hour)) + "h"
+	default:
+		amount = strconv.Itoa(int(distance/(24*time.Hour))) + "d"
+	}
+
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
+
+// InZone moves every alert's timestamp to a zone, so everything derived from
+// it is rendered there too
+func (alerts *Alerts) InZone(location *time.Location) {
+	for i := range alerts.Alerts {
+		alerts.Alerts[i].Timestamp = alerts.Alerts[i].Timestamp.In(location)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	expected := time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC)
+	valid := []interface{}{
+		"2025-04-22T09:00:00Z",
+		"2025-04-22T12:00:00+03:00",
+		"2025-04-22T12:00:00+0300",
+		"2025-04-22T12:00:00+03",
+		"2025-04-22 12:00:00+03:00",
+		"2025-04-22 05:00:00 -0400",
+		"2025-04-22T09:00:00.000Z",
+		"Tue, 22 Apr 2025 12:00:00 +0300",
+		float64(1745312400),
+		float64(1745312400000),
+		"1745312400",
+	}
+	for _, value := range valid {
+		parsed, err := parseTimestamp(value)
+		if err != nil {
+			t.Errorf("Unexpected error for %v: %v", value, err)
+			continue
+		}
+		if !parsed.Equal(expected) {
+			t.Errorf("Expected %v to be %v, got %v", value, expected, parsed)
+		}
+	}
+
+	for _, value := range []string{"2025-04-22T12:00:00", "2025-04-22 12:00", "2025-04-22"} {
+		if _, err := parseTimestamp(value); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+			t.Errorf("Expected %q to be refused as ambiguous, got %v", value, err)
+		}
+	}
+	if _, err := parseTimestamp("yesterday"); err == nil {
+		t.Error("Expected an error for an unrecognized timestamp")
+	}
+}
+
+func TestLoadAlertsWithTimestampFormats(t *testing.T) {
+	content := `{"alerts": [
+		{"id": "ALT-1", "timestamp": "2025-04-22T12:00:00+0300", "service": "web", "severity": "critical"},
+		{"id": "ALT-2", "timestamp": 1745312400, "service": "web", "severity": "critical"}
+	]}`
+	testFile := createTestFile(t, content)
+	defer os.Remove(testFile)
+
+	alerts, err := loadAlertsFromFile(testFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !alerts.Alerts[0].Timestamp.Equal(alerts.Alerts[1].Timestamp) {
+		t.Errorf("Expected both timestamps to be the same instant, got %v and %v",
+			alerts.Alerts[0].Timestamp, alerts.Alerts[1].Timestamp)
+	}
+
+	ambiguous := createTestFile(t, `{"alerts": [{"id": "ALT-3", "timestamp": "2025-04-22 12:00:00", "service": "web"}]}`)
+	defer os.Remove(ambiguous)
+	_, err = loadAlertsFromFile(ambiguous)
+	if err == nil || !strings.Contains(err.Error(), "ALT-3") || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected an ambiguous timestamp error naming ALT-3, got %v", err)
+	}
+}
+
+func TestTimeDisplay(t *testing.T) {
+	helsinki, err := parseTimezone("Europe/Helsinki")
+	if err != nil {
+		t.Skipf("No timezone database: %v", err)
+	}
+	fired := time.Date(2025, 4, 22, 9, 0, 0, 0, time.UTC)
+	now := func() time.Time { return fired.Add(12*time.Minute + 30*time.Second) }
+
+	if text := (TimeDisplay{}).Format(fired); text != "2025-04-22 09:00:00 UTC" {
+		t.Errorf("Expected the parsed zone by default, got %s", text)
+	}
+	display := TimeDisplay{Location: helsinki, Relative: true, Now: now}
+	if text := display.Format(fired); text != "2025-04-22 12:00:00 EEST (12m ago)" {
+		t.Errorf("Expected Helsinki time and a relative time, got %s", text)
+	}
+
+	if _, err := parseTimezone("Mars/Olympus"); err == nil {
+		t.Error("Expected an error for an unknown timezone")
+	}
+	if location, _ := parseTimezone("local"); location != time.Local {
+		t.Errorf("Expected local to be the local zone, got %v", location)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2025, 4, 22, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago      time.Duration
+		expected string
+	}{
+		{20 * time.Second, "just now"},
+		{12 * time.Minute, "12m ago"},
+		{3*time.Hour + 59*time.Minute, "3h ago"},
+		{50 * time.Hour, "2d ago"},
+		{-5 * time.Minute, "in 5m"},
+	}
+	for _, test := range tests {
+		if text := relativeTime(now.Add(-test.ago), now); text != test.expected {
+			t.Errorf("Expected %s for %v ago, got %s", test.expected, test.ago, text)
+		}
+	}
+}
+
+func TestTrendsInTimezone(t *testing.T) {
+	kolkata, err := parseTimezone("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("No timezone database: %v", err)
+	}
+	alerts := Alerts{Alerts: []Alert{
+		{ID: "ALT-1", Service: "web", Severity: "critical", Timestamp: mustParseTime(t, "2025-04-22T09:10:00Z")},
+	}}
+
+	// 09:10 UTC is 14:40 in Kolkata, so the hour starts at 14:00 there
+	trends := buildTrends(alerts, time.Hour, kolkata)
+	if trends.Start.Location() != kolkata || trends.Start.Hour() != 14 || trends.Start.Minute() != 0 {
+		t.Errorf("Expected the window to start at 14:00 in Kolkata, got %v", trends.Start)
+	}
+	if trends.Total != 1 {
+		t.Errorf("Expected the alert in the window, got %d", trends.Total)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sparkLevels are the bar heights of a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// trendSeverities are the severities with their own column in the hourly table
+var trendSeverities = []string{"critical", "warning", "info"}
+
+// HourBucket counts the alerts that fired within one hour
+type HourBucket struct {
+	Start      time.Time      `json:"start"`
+	Total      int            `json:"total"`
+	Severities map[string]int `json:"severities"`
+}
+
+// ServiceTrend is the hourly alert count of one service
+type ServiceTrend struct {
+	Service   string `json:"service"`
+	Total     int    `json:"total"`
+	Hourly    []int  `json:"hourly"`
+	Sparkline string `json:"sparkline"`
+}
+
+// WindowComparison compares a window to the previous window of equal length
+type WindowComparison struct {
+	Start         time.Time      `json:"start"`
+	Total         int            `json:"total"`
+	Severities    map[string]int `json:"severities"`
+	Change        int            `json:"change"`
+	ChangePercent *float64       `json:"change_percent,omitempty"` // Unset when the previous window was empty
+}
+
+// TrendStats are time-bucketed statistics over a window ending at the
+// latest alert
+type TrendStats struct {
+	Start                    time.Time        `json:"start"`
+	End                      time.Time        `json:"end"`
+	WindowHours              int              `json:"window_hours"`
+	Total                    int              `json:"total"`
+	Hours                    []HourBucket     `json:"hours"`
+	Services                 []ServiceTrend   `json:"services"`
+	MeanSecondsBetweenAlerts float64          `json:"mean_seconds_between_alerts"`
+	BusiestHour              *HourBucket      `json:"busiest_hour,omitempty"`
+	Previous                 WindowComparison `json:"previous"`
+}
+
+// buildTrends buckets the alerts of the window ending with the hour of the
+// latest alert. The window is rounded up to whole hours of the given zone,
+// UTC if nil.
+func buildTrends(alerts Alerts, window time.Duration, location *time.Location) TrendStats {
+	hours := int(math.Ceil(window.Hours()))
+	if hours < 1 {
+		hours = 1
+	}
+
+	var latest time.Time
+	for _, alert := range alerts.Alerts {
+		if alert.Timestamp.After(latest) {
+			latest = alert.Timestamp
+		}
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	latest = latest.In(location)
+	end := time.Date(latest.Year(), latest.Month(), latest.Day(), latest.Hour(), 0, 0, 0, location).Add(time.Hour)
+	start := end.Add(-time.Duration(hours) * time.Hour)
+
+	trends := TrendStats{
+		Start:       start,
+		End:         end,
+		WindowHours: hours,
+		Hours:       make([]HourBucket, hours),
+		Previous: WindowComparison{
+			Start:      start.Add(-time.Duration(hours) * time.Hour),
+			Severities: make(map[string]int),
+		},
+	}
+	for i := range trends.Hours {
+		trends.Hours[i] = HourBucket{Start: start.Add(time.Duration(i) * time.Hour), Severities: make(map[string]int)}
+	}
+
+	services := make(map[string]*ServiceTrend)
+	var times []time.Time
+	for _, alert := range alerts.Alerts {
+		severity := strings.ToLower(alert.Severity)
+		if !alert.Timestamp.Before(trends.Previous.Start) && alert.Timestamp.Before(start) {
+			trends.Previous.Total++
+			trends.Previous.Severities[severity]++
+			continue
+		}
+		if alert.Timestamp.Before(start) || !alert.Timestamp.Before(end) {
+			continue
+		}
+
+		hour := int(alert.Timestamp.Sub(start) / time.Hour)
+		trends.Total++
+		trends.Hours[hour].Total++
+		trends.Hours[hour].Severities[severity]++
+		times = append(times, alert.Timestamp)
+
+		service, exists := services[alert.Service]
+		if !exists {
+			service = &ServiceTrend{Service: alert.Service, Hourly: make([]int, hours)}
+			services[alert.Service] = service
+		}
+		service.Total++
+		service.Hourly[hour]++
+	}
+
+	for _, service := range services {
+		service.Sparkline = sparkline(service.Hourly)
+		trends.Services = append(trends.Services, *service)
+	}
+	sort.Slice(trends.Services, func(i, j int) bool {
+		if trends.Services[i].Total != trends.Services[j].Total {
+			return trends.Services[i].Total > trends.Services[j].Total
+		}
+		return trends.Services[i].Service < trends.Services[j].Service
+	})
+
+	if len(times) > 1 {
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+		trends.MeanSecondsBetweenAlerts = times[len(times)-1].Sub(times[0]).Seconds() / float64(len(times)-1)
+	}
+
+	for i := range trends.Hours {
+		if trends.Hours[i].Total > 0 && (trends.BusiestHour == nil || trends.Hours[i].Total > trends.BusiestHour.Total) {
+			trends.BusiestHour = &trends.Hours[i]
+		}
+	}
+
+	trends.Previous.Change = trends.Total - trends.Previous.Total
+	if trends.Previous.Total > 0 {
+		percent := float64(trends.Previous.Change) / float64(trends.Previous.Total) * 100
+		trends.Previous.ChangePercent = &percent
+	}
+
+	return trends
+}
+
+// sparkline draws counts as bars scaled to the largest count. Any non-zero
+// count is drawn above the lowest bar so single alerts stay visible.
+func sparkline(counts []int) string {
+	max := 0
+	for _, count := range counts {
+		if count > max {
+			max = count
+		}
+	}
+
+	var line strings.Builder
+	for _, count := range counts {
+		level := 0
+		if max > 0 {
+			level = int(math.Ceil(float64(count) * float64(len(sparkLevels)-1) / float64(max)))
+		}
+		line.WriteRune(sparkLevels[level])
+	}
+	return line.String()
+}
+
+func showTrends(w io.Writer, trends TrendStats, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(trends, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding trends: %v", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintf(w, "📈 TRENDS: last %dh (%s to %s)\n", trends.WindowHours,
+		trends.Start.Format("2006-01-02 15:04"), trends.End.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+
+	fmt.Fprintln(w, "\n🕐 Alerts per Hour:")
+	fmt.Fprintf(w, "  %-16s %8s %8s %8s %8s\n", "Hour", "Critical", "Warning", "Info", "Total")
+	for _, hour := range trends.Hours {
+		if hour.Total == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "  %-16s", hour.Start.Format("2006-01-02 15:04"))
+		for _, severity := range trendSeverities {
+			fmt.Fprintf(w, " %8d", hour.Severities[severity])
+		}
+		fmt.Fprintf(w, " %8d\n", hour.Total)
+	}
+
+	fmt.Fprintln(w, "\n🏢 Alerts per Service:")
+	for _, service := range trends.Services {
+		fmt.Fprintf(w, "  %-30s %s %d\n", service.Service, service.Sparkline, service.Total)
+	}
+
+	fmt.Fprintln(w)
+	if trends.Total > 1 {
+		mean := time.Duration(trends.MeanSecondsBetweenAlerts * float64(time.Second)).Round(time.Second)
+		fmt.Fprintf(w, "⏱️  Mean time between alerts: %s\n", mean)
+	}
+	if trends.BusiestHour != nil {
+		fmt.Fprintf(w, "🔥 Busiest hour: %s (%d alerts)\n",
+			trends.BusiestHour.Start.Format("2006-01-02 15:04"), trends.BusiestHour.Total)
+	}
+
+	fmt.Fprintf(w, "↔️  Previous %dh: %d alerts, now %d", trends.WindowHours, trends.Previous.Total, trends.Total)
+	if trends.Previous.ChangePercent != nil {
+		fmt.Fprintf(w, " (%+.1f%%)", *trends.Previous.ChangePercent)
+	}
+	fmt.Fprintln(w)
+	for _, severity := range trendSeverities {
+		current := 0
+		for _, hour := range trends.Hours {
+			current += hour.Severities[severity]
+		}
+		if current > 0 || trends.Previous.Severities[severity] > 0 {
+			fmt.Fprintf(w, "  %s: %d → %d\n", strings.Title(severity), trends.Previous.Severities[severity], current)
+		}
+	}
+
+	return nil
+}
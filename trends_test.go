@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBuildTrends(t *testing.T) {
+	base := mustParseTime(t, "2024-04-28T10:00:00Z")
+	alerts := Alerts{Alerts: []Alert{
+		// Previous window
+		{ID: "ALT-1", Service: "web", Severity: "warning", Timestamp: base.Add(-90 * time.Minute)},
+		{ID: "ALT-2", Service: "db", Severity: "critical", Timestamp: base.Add(-75 * time.Minute)},
+		// Current window: 10:00 to 12:00
+		{ID: "ALT-3", Service: "web", Severity: "critical", Timestamp: base.Add(5 * time.Minute)},
+		{ID: "ALT-4", Service: "web", Severity: "warning", Timestamp: base.Add(65 * time.Minute)},
+		{ID: "ALT-5", Service: "web", Severity: "info", Timestamp: base.Add(70 * time.Minute)},
+		{ID: "ALT-6", Service: "db", Severity: "Critical", Timestamp: base.Add(95 * time.Minute)},
+		// Before both windows
+		{ID: "ALT-7", Service: "db", Severity: "info", Timestamp: base.Add(-5 * time.Hour)},
+	}}
+
+	trends := buildTrends(alerts, 2*time.Hour, nil)
+
+	if !trends.Start.Equal(base) || !trends.End.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("Expected window 10:00 to 12:00, got %v to %v", trends.Start, trends.End)
+	}
+	if trends.Total != 4 || trends.Hours[0].Total != 1 || trends.Hours[1].Total != 3 {
+		t.Errorf("Unexpected hourly totals: %+v", trends.Hours)
+	}
+	if trends.Hours[1].Severities["critical"] != 1 {
+		t.Errorf("Expected severities to be counted case-insensitively, got %v", trends.Hours[1].Severities)
+	}
+	if trends.BusiestHour == nil || !trends.BusiestHour.Start.Equal(base.Add(time.Hour)) {
+		t.Errorf("Expected 11:00 to be the busiest hour, got %+v", trends.BusiestHour)
+	}
+	if trends.MeanSecondsBetweenAlerts != 30*60 {
+		t.Errorf("Expected 30 minutes between alerts, got %.0fs", trends.MeanSecondsBetweenAlerts)
+	}
+
+	if len(trends.Services) != 2 || trends.Services[0].Service != "web" || trends.Services[0].Sparkline != "▅█" {
+		t.Errorf("Unexpected service trends: %+v", trends.Services)
+	}
+
+	if trends.Previous.Total != 2 || trends.Previous.Change != 2 || *trends.Previous.ChangePercent != 100 {
+		t.Errorf("Unexpected comparison to the previous window: %+v", trends.Previous)
+	}
+
+	if _, err := json.Marshal(trends); err != nil {
+		t.Errorf("Expected trends to encode as JSON, got: %v", err)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		counts   []int
+		expected string
+	}{
+		{[]int{0, 0, 0}, "▁▁▁"},
+		{[]int{0, 1, 7}, "▁▂█"},
+		{[]int{1, 100}, "▂█"},
+	}
+
+	for _, test := range tests {
+		if got := sparkline(test.counts); got != test.expected {
+			t.Errorf("sparkline(%v) = %s, expected %s", test.counts, got, test.expected)
+		}
+	}
+}
+
+func TestParseFlags_InvalidTrends(t *testing.T) {
+	resetFlags()
+
+	testFile := createTestFile(t, testJSONContent)
+	defer os.Remove(testFile)
+
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--trends=csv"}
+	if _, err := parseFlags(); err == nil {
+		t.Error("Expected error for invalid trends format, got nil")
+	}
+
+	resetFlags()
+	os.Args = []string{"enc-alertbuddy", "-i", testFile, "--trends=json", "--trends-window=30m"}
+	if _, err := parseFlags(); err == nil {
+		t.Error("Expected error for a trends window under an hour, got nil")
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Validation levels
+const (
+	levelError   = "error"
+	levelWarning = "warning"
+	levelOff     = "off"
+)
+
+// ValidationRule is a check on every alert of an input file
+type ValidationRule struct {
+	Name        string
+	Level       string // Default level
+	Description string
+	check       func(alert Alert, severities *SeverityModel) string // Returns a message when the alert breaks the rule
+}
+
+// validationRules are the checks run on input files, in report order.
+// duplicate-id needs all alerts and is handled by Validate itself.
+var validationRules = []ValidationRule{
+	{
+		Name: "empty-id", Level: levelError, Description: "alert has no ID",
+		check: func(alert Alert, severities *SeverityModel) string {
+			if strings.TrimSpace(alert.ID) == "" {
+				return "id is empty"
+			}
+			return ""
+		},
+	},
+	{
+		Name: "duplicate-id", Level: levelError, Description: "ID is used by an earlier alert",
+	},
+	{
+		Name: "unknown-severity", Level: levelError, Description: "severity is not on the severity ladder",
+		check: func(alert Alert, severities *SeverityModel) string {
+			if _, ok := severities.Rank(alert.Severity); ok {
+				return ""
+			}
+			return fmt.Sprintf("severity '%s' is not one of %s, so it is scored as %s",
+				alert.Severity, strings.Join(severities.Names(), ", "), severities.Level(alert.Severity).Name)
+		},
+	},
+	{
+		Name: "zero-threshold", Level: levelWarning, Description: "threshold is 0, so the deviation term is always 0",
+		check: func(alert Alert, severities *SeverityModel) string {
+			if alert.Threshold == 0 {
+				return "threshold is 0, so the deviation from it is not scored"
+			}
+			return ""
+		},
+	},
+	{
+		Name: "zero-timestamp", Level: levelError, Description: "timestamp is missing",
+		check: func(alert Alert, severities *SeverityModel) string {
+			if alert.Timestamp.IsZero() {
+				return "timestamp is missing or zero"
+			}
+			return ""
+		},
+	},
+}
+
+// ValidationProblem is a rule broken by one alert
+type ValidationProblem struct {
+	Index   int    `json:"index"` // Position of the alert in the input file
+	ID      string `json:"id"`
+	Rule    string `json:"rule"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// parseValidationLevels parses per-rule levels such as
+// "zero-threshold=error,duplicate-id=off" over the rules' defaults
+func parseValidationLevels(spec string) (map[string]string, error) {
+	levels := make(map[string]string, len(validationRules))
+	for _, rule := range validationRules {
+		levels[rule.Name] = rule.Level
+	}
+
+	for _, item := range splitList(spec) {
+		name, level, found := strings.Cut(item, "=")
+		name, level = strings.TrimSpace(name), strings.ToLower(strings.TrimSpace(level))
+		if _, known := levels[name]; !known {
+			return nil, fmt.Errorf("unknown validation rule '%s'. Valid rules: %s", name, strings.Join(validationRuleNames(), ", "))
+		}
+		if !found || (level != levelError && level != levelWarning && level != levelOff) {
+			return nil, fmt.Errorf("invalid level for rule '%s'. Use %s=error, %s=warning or %s=off", name, name, name, name)
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+func validationRuleNames() []string {
+	names := make([]string, len(validationRules))
+	for i, rule := range validationRules {
+		names[i] = rule.Name
+	}
+	return names
+}
+
+// Validate checks every alert against the validation rules at the given
+// levels, with severities from the given ladder. Problems are ordered by
+// alert, then by rule.
+func (alerts Alerts) Validate(levels map[string]string, severities *SeverityModel) []ValidationProblem {
+	var problems []ValidationProblem
+	firstIndex := make(map[string]int)
+	if severities == nil {
+		severities = defaultSeverities
+	}
+
+	for i, alert := range alerts.Alerts {
+		for _, rule := range validationRules {
+			level := levels[rule.Name]
+			if level == "" {
+				level = rule.Level
+			}
+			if level == levelOff {
+				continue
+			}
+
+			var message string
+			if rule.Name == "duplicate-id" {
+				if first, seen := firstIndex[alert.ID]; seen && alert.ID != "" {
+					message = fmt.Sprintf("id is already used by alerts[%d]", first)
+				}
+			} else {
+				message = rule.check(alert, severities)
+			}
+
+			if message != "" {
+				problems = append(problems, ValidationProblem{Index: i, ID: alert.ID, Rule: rule.Name, Level: level, Message: message})
+			}
+		}
+		if _, seen := firstIndex[alert.ID]; !seen {
+			firstIndex[alert.ID] = i
+		}
+	}
+
+	return problems
+}
+
+// countProblems returns the number of errors and warnings
+func countProblems(problems []ValidationProblem) (int, int) {
+	var errors, warnings int
+	for _, problem := range problems {
+		if problem.Level == levelError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// ValidationReport holds the problems found in an input file
+type ValidationReport struct {
+	File         string              `json:"file"`
+	Alerts       int                 `json:"alerts"`
+	Errors       int                 `json:"errors"` // Including schema errors
+	Warnings     int                 `json:"warnings"`
+	SchemaErrors []SchemaError       `json:"schema_errors,omitempty"`
+	Problems     []ValidationProblem `json:"problems"`
+}
+
+// newValidationReport counts the problems of a file. alerts is nil when the
+// file could not be loaded.
+func newValidationReport(filename string, alerts *Alerts, schemaErrors []SchemaError, problems []ValidationProblem) ValidationReport {
+	report := ValidationReport{File: filename, SchemaErrors: schemaErrors, Problems: problems}
+	if report.Problems == nil {
+		report.Problems = []ValidationProblem{} // An empty list rather than null in JSON
+	}
+	if alerts != nil {
+		report.Alerts = len(alerts.Alerts)
+	}
+	report.Errors, report.Warnings = countProblems(problems)
+	report.Errors += len(schemaErrors)
+	return report
+}
+
+func showValidationReport(w io.Writer, report ValidationReport) {
+	filename := report.File
+	showSchemaErrors(w, filename, report.SchemaErrors)
+	for _, problem := range report.Problems {
+		icon := "⚠️ "
+		if problem.Level == levelError {
+			icon = "❌"
+		}
+		id := problem.ID
+		if id == "" {
+			id = "(no id)"
+		}
+		fmt.Fprintf(w, "%s %s: alerts[%d] %s: %s: %s\n", icon, filename, problem.Index, id, problem.Rule, problem.Message)
+	}
+
+	fmt.Fprintf(w, "🔎 %s: %d errors, %d warnings\n", filename, report.Errors, report.Warnings)
+}
+
+// checkStrict validates alerts in --strict mode, failing on errors
+func checkStrict(alerts Alerts, config *Config) error {
+	if !config.Strict {
+		return nil
+	}
+
+	problems := alerts.Validate(config.ruleLevels, config.severities)
+	if len(problems) == 0 {
+		return nil
+	}
+	report := newValidationReport(config.InputFile, &alerts, nil, problems)
+	showValidationReport(config.reportWriter(), report)
+	if report.Errors > 0 {
+		return fmt.Errorf("'%s' failed validation with %d errors", config.InputFile, report.Errors)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createValidationAlerts() Alerts {
+	now := time.Date(2025, 4, 22, 12, 0, 0, 0, time.UTC)
+	return Alerts{Alerts: []Alert{
+		{ID: "ALT-1", Severity: "critical", Threshold: 80, Timestamp: now},
+		{ID: "", Severity: "warning", Threshold: 80, Timestamp: now},
+		{ID: "ALT-3", Severity: "urgent", Threshold: 0, Timestamp: now},
+		{ID: "ALT-1", Severity: "Info", Threshold: 80},
+	}}
+}
+
+func TestValidate(t *testing.T) {
+	alerts := createValidationAlerts()
+	levels, err := parseValidationLevels("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	problems := alerts.Validate(levels, defaultSeverities)
+	expected := []struct {
+		index int
+		rule  string
+		level string
+	}{
+		{1, "empty-id", levelError},
+		{2, "unknown-severity", levelError},
+		{2, "zero-threshold", levelWarning},
+		{3, "duplicate-id", levelError},
+		{3, "zero-timestamp", levelError},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, want := range expected {
+		got := problems[i]
+		if got.Index != want.index || got.Rule != want.rule || got.Level != want.level {
+			t.Errorf("Expected problem %d to be %+v, got %+v", i, want, got)
+		}
+	}
+	if !strings.Contains(problems[3].Message, "alerts[0]") {
+		t.Errorf("Expected the duplicate to point at the first alert, got %q", problems[3].Message)
+	}
+
+	errors, warnings := countProblems(problems)
+	if errors != 4 || warnings != 1 {
+		t.Errorf("Expected 4 errors and 1 warning, got %d and %d", errors, warnings)
+	}
+}
+
+func TestValidateLevels(t *testing.T) {
+	alerts := createValidationAlerts()
+	levels, err := parseValidationLevels("zero-threshold=error, duplicate-id=off,zero-timestamp=WARNING")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	problems := alerts.Validate(levels, defaultSeverities)
+	for _, problem := range problems {
+		switch problem.Rule {
+		case "duplicate-id":
+			t.Errorf("Expected duplicate-id to be off, got %+v", problem)
+		case "zero-threshold":
+			if problem.Level != levelError {
+				t.Errorf("Expected zero-threshold at error level, got %s", problem.Level)
+			}
+		case "zero-timestamp":
+			if problem.Level != levelWarning {
+				t.Errorf("Expected zero-timestamp at warning level, got %s", problem.Level)
+			}
+		}
+	}
+
+	for _, spec := range []string{"no-such-rule=error", "empty-id=fatal", "empty-id"} {
+		if _, err := parseValidationLevels(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestShowValidationReport(t *testing.T) {
+	alerts := createValidationAlerts()
+	report := newValidationReport("alerts.json", &alerts, nil, alerts.Validate(nil, defaultSeverities))
+
+	var out bytes.Buffer
+	showValidationReport(&out, report)
+	text := out.String()
+	if !strings.Contains(text, "alerts.json: alerts[1] (no id): empty-id") {
+		t.Errorf("Expected the empty ID to be reported, got:\n%s", text)
+	}
+	if !strings.Contains(text, "4 errors, 1 warnings") {
+		t.Errorf("Expected the totals in the report, got:\n%s", text)
+	}
+}
+
+func TestCheckStrict(t *testing.T) {
+	alerts := createValidationAlerts()
+	config := newConfig()
+	config.reports = &bytes.Buffer{}
+
+	if err := checkStrict(alerts, config); err != nil {
+		t.Errorf("Expected no error without --strict, got %v", err)
+	}
+
+	config.Strict = true
+	if err := checkStrict(alerts, config); err == nil {
+		t.Error("Expected an error in strict mode")
+	}
+
+	config.ruleLevels, _ = parseValidationLevels("empty-id=warning,unknown-severity=off,duplicate-id=off,zero-timestamp=off")
+	if err := checkStrict(alerts, config); err != nil {
+		t.Errorf("Expected warnings only to pass, got %v", err)
+	}
+}